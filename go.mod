@@ -1,5 +1,7 @@
 module github.com/takashabe/btcli
 
+go 1.19
+
 require (
 	cloud.google.com/go v0.34.0
 	github.com/c-bata/go-prompt v0.2.3
@@ -21,11 +23,11 @@ require (
 	golang.org/x/net v0.0.0-20181217023233-e147a9138326 // indirect
 	golang.org/x/oauth2 v0.0.0-20181203162652-d668ce993890
 	golang.org/x/sync v0.0.0-20181108010431-42b317875d0f // indirect
-	golang.org/x/sys v0.0.0-20181217223516-dcdaa6325bcb // indirect
-	google.golang.org/api v0.0.0-20181217000635-41dc4b66e69d // indirect
+	golang.org/x/sys v0.0.0-20181217223516-dcdaa6325bcb
+	google.golang.org/api v0.0.0-20181217000635-41dc4b66e69d
 	google.golang.org/appengine v1.3.0 // indirect
 	google.golang.org/genproto v0.0.0-20181218023534-67d6565462c5 // indirect
-	google.golang.org/grpc v1.17.0 // indirect
+	google.golang.org/grpc v1.17.0
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
@@ -39,8 +39,20 @@ type Client interface {
 
 	Get(ctx context.Context, table, key string, opts ...bigtable.ReadOption) (*Bigtable, error)
 	GetRows(ctx context.Context, table string, rr bigtable.RowRange, opts ...bigtable.ReadOption) (*Bigtable, error)
-	Count(ctx context.Context, table string) (int, error)
+	ReadRows(ctx context.Context, table string, rr bigtable.RowRange, fn func(*Row) bool, opts ...bigtable.ReadOption) error
+	SampleRowKeys(ctx context.Context, table string) ([]string, error)
+	Count(ctx context.Context, table string, rr bigtable.RowRange, opts ...bigtable.ReadOption) (int, error)
+	CountBy(ctx context.Context, table string, rr bigtable.RowRange, groupFn func(key string) string, opts ...bigtable.ReadOption) (map[string]int, error)
 	Tables(ctx context.Context) ([]string, error)
+	Families(ctx context.Context, table string) ([]string, error)
+
+	// ApplyBulk applies the mutations to the rows, returns the errors of each row or the error of the whole request
+	ApplyBulk(ctx context.Context, table string, keys []string, muts []*bigtable.Mutation) ([]error, error)
+
+	// Stats returns the statistics of the requests since the client is created
+	Stats() Stats
+	// Close closes the connections of the client
+	Close() error
 }
 
 type client struct {
@@ -48,6 +60,7 @@ type client struct {
 	adminClient *bigtable.AdminClient
 	outStream   io.Writer
 	errStream   io.Writer
+	recorder    *recorder
 }
 
 // Option functional option pattern for the client.
@@ -55,18 +68,24 @@ type Option func(*client)
 
 // NewClient returns initialized client.
 func NewClient(project, instance string, opts ...Option) (Client, error) {
-	cli, err := getClient(project, instance)
+	r := &recorder{}
+	cli, err := getClient(project, instance, r)
 	if err != nil {
 		return nil, err
 	}
-	adminClient, err := getAdminClient(project, instance)
+	adminClient, err := getAdminClient(project, instance, r)
 	if err != nil {
 		return nil, err
 	}
-	return &client{
+	c := &client{
 		client:      cli,
 		adminClient: adminClient,
-	}, nil
+		recorder:    r,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
 }
 
 // WithOutStream settings outStream
@@ -83,14 +102,20 @@ func WithErrStream(w io.Writer) Option {
 	}
 }
 
-func getClient(project, instance string) (*bigtable.Client, error) {
-	// TODO: Support options
-	return bigtable.NewClient(context.Background(), project, instance)
+func getClient(project, instance string, r *recorder) (*bigtable.Client, error) {
+	opts, err := r.clientOptions()
+	if err != nil {
+		return nil, err
+	}
+	return bigtable.NewClient(context.Background(), project, instance, opts...)
 }
 
-func getAdminClient(project, instance string) (*bigtable.AdminClient, error) {
-	// TODO: Support options
-	return bigtable.NewAdminClient(context.Background(), project, instance)
+func getAdminClient(project, instance string, r *recorder) (*bigtable.AdminClient, error) {
+	opts, err := r.clientOptions()
+	if err != nil {
+		return nil, err
+	}
+	return bigtable.NewAdminClient(context.Background(), project, instance, opts...)
 }
 
 func (c *client) OutStream() io.Writer {
@@ -107,15 +132,36 @@ func (c *client) ErrStream() io.Writer {
 	return c.errStream
 }
 
+// redirectClient is the client that writes the output to the other stream
+type redirectClient struct {
+	Client
+	outStream io.Writer
+}
+
+// RedirectOutStream returns the client that writes the output to w instead of the OutStream of c.
+func RedirectOutStream(c Client, w io.Writer) Client {
+	return &redirectClient{
+		Client:    c,
+		outStream: w,
+	}
+}
+
+func (c *redirectClient) OutStream() io.Writer {
+	return c.outStream
+}
+
 func (c *client) Get(ctx context.Context, table, key string, opts ...bigtable.ReadOption) (*Bigtable, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	tbl := c.client.Open(table)
-	row, err := tbl.ReadRow(ctx, key, opts...)
+	row, err := tbl.ReadRow(withAttempts(ctx), key, opts...)
 	if err != nil {
 		return nil, err
 	}
+	if len(row) > 0 {
+		c.recorder.addRow(row)
+	}
 	return &Bigtable{
 		Table: table,
 		Rows: []*Row{
@@ -130,7 +176,8 @@ func (c *client) GetRows(ctx context.Context, table string, rr bigtable.RowRange
 
 	tbl := c.client.Open(table)
 	rows := []*Row{}
-	err := tbl.ReadRows(ctx, rr, func(row bigtable.Row) bool {
+	err := tbl.ReadRows(withAttempts(ctx), rr, func(row bigtable.Row) bool {
+		c.recorder.addRow(row)
 		rows = append(rows, readRow(row))
 		return true
 	}, opts...)
@@ -143,19 +190,54 @@ func (c *client) GetRows(ctx context.Context, table string, rr bigtable.RowRange
 	}, nil
 }
 
-func (c *client) Count(ctx context.Context, table string) (int, error) {
+// ReadRows streams the rows to fn until fn returns false.
+// It has no timeout because it's intended for long scans.
+func (c *client) ReadRows(ctx context.Context, table string, rr bigtable.RowRange, fn func(*Row) bool, opts ...bigtable.ReadOption) error {
+	tbl := c.client.Open(table)
+	return tbl.ReadRows(withAttempts(ctx), rr, func(row bigtable.Row) bool {
+		c.recorder.addRow(row)
+		return fn(readRow(row))
+	}, opts...)
+}
+
+func (c *client) SampleRowKeys(ctx context.Context, table string) ([]string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	tbl := c.client.Open(table)
+	return tbl.SampleRowKeys(withAttempts(ctx))
+}
+
+func (c *client) Count(ctx context.Context, table string, rr bigtable.RowRange, opts ...bigtable.ReadOption) (int, error) {
 	cnt := 0
-	err := tbl.ReadRows(ctx, bigtable.InfiniteRange(""), func(_ bigtable.Row) bool {
+	err := c.count(ctx, table, rr, func(_ string) {
 		cnt++
-		return true
-	}, bigtable.RowFilter(bigtable.StripValueFilter()))
+	}, opts...)
 	return cnt, err
 }
 
+func (c *client) CountBy(ctx context.Context, table string, rr bigtable.RowRange, groupFn func(key string) string, opts ...bigtable.ReadOption) (map[string]int, error) {
+	cnts := map[string]int{}
+	err := c.count(ctx, table, rr, func(key string) {
+		cnts[groupFn(key)]++
+	}, opts...)
+	return cnts, err
+}
+
+// count invokes fn with each row key, strip values when no options are given.
+// It has no timeout as well as ReadRows, because counting the large table is a long scan.
+func (c *client) count(ctx context.Context, table string, rr bigtable.RowRange, fn func(key string), opts ...bigtable.ReadOption) error {
+	if len(opts) == 0 {
+		opts = []bigtable.ReadOption{bigtable.RowFilter(bigtable.StripValueFilter())}
+	}
+	tbl := c.client.Open(table)
+	return tbl.ReadRows(withAttempts(ctx), rr, func(row bigtable.Row) bool {
+		c.recorder.addRow(row)
+		fn(row.Key())
+		return true
+	}, opts...)
+}
+
 func readRow(r bigtable.Row) *Row {
 	ret := &Row{
 		Key:     r.Key(),
@@ -180,14 +262,48 @@ func readRow(r bigtable.Row) *Row {
 	return ret
 }
 
+// ApplyBulk has no timeout as well as ReadRows, the caller splits the rows into the batches.
+func (c *client) ApplyBulk(ctx context.Context, table string, keys []string, muts []*bigtable.Mutation) ([]error, error) {
+	tbl := c.client.Open(table)
+	return tbl.ApplyBulk(withAttempts(ctx), keys, muts)
+}
+
 func (c *client) Tables(ctx context.Context) ([]string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	tbls, err := c.adminClient.Tables(ctx)
+	tbls, err := c.adminClient.Tables(withAttempts(ctx))
 	if err != nil {
 		return []string{}, err
 	}
 	sort.Strings(tbls)
 	return tbls, nil
 }
+
+func (c *client) Families(ctx context.Context, table string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	ti, err := c.adminClient.TableInfo(withAttempts(ctx), table)
+	if err != nil {
+		return []string{}, err
+	}
+	fams := make([]string, 0, len(ti.FamilyInfos))
+	for _, fi := range ti.FamilyInfos {
+		fams = append(fams, fi.Name)
+	}
+	sort.Strings(fams)
+	return fams, nil
+}
+
+func (c *client) Stats() Stats {
+	return c.recorder.stats()
+}
+
+func (c *client) Close() error {
+	err := c.client.Close()
+	if aerr := c.adminClient.Close(); err == nil {
+		err = aerr
+	}
+	return err
+}
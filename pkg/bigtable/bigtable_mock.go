@@ -103,19 +103,78 @@ func (mr *MockClientMockRecorder) GetRows(ctx, table, rr interface{}, opts ...in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRows", reflect.TypeOf((*MockClient)(nil).GetRows), varargs...)
 }
 
+// ReadRows mocks base method
+func (m *MockClient) ReadRows(ctx context.Context, table string, rr bigtable.RowRange, fn func(*Row) bool, opts ...bigtable.ReadOption) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, table, rr, fn}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReadRows", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReadRows indicates an expected call of ReadRows
+func (mr *MockClientMockRecorder) ReadRows(ctx, table, rr, fn interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, table, rr, fn}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRows", reflect.TypeOf((*MockClient)(nil).ReadRows), varargs...)
+}
+
+// SampleRowKeys mocks base method
+func (m *MockClient) SampleRowKeys(ctx context.Context, table string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SampleRowKeys", ctx, table)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SampleRowKeys indicates an expected call of SampleRowKeys
+func (mr *MockClientMockRecorder) SampleRowKeys(ctx, table interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SampleRowKeys", reflect.TypeOf((*MockClient)(nil).SampleRowKeys), ctx, table)
+}
+
 // Count mocks base method
-func (m *MockClient) Count(ctx context.Context, table string) (int, error) {
+func (m *MockClient) Count(ctx context.Context, table string, rr bigtable.RowRange, opts ...bigtable.ReadOption) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Count", ctx, table)
+	varargs := []interface{}{ctx, table, rr}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Count", varargs...)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Count indicates an expected call of Count
-func (mr *MockClientMockRecorder) Count(ctx, table interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) Count(ctx, table, rr interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, table, rr}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockClient)(nil).Count), varargs...)
+}
+
+// CountBy mocks base method
+func (m *MockClient) CountBy(ctx context.Context, table string, rr bigtable.RowRange, groupFn func(string) string, opts ...bigtable.ReadOption) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, table, rr, groupFn}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CountBy", varargs...)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountBy indicates an expected call of CountBy
+func (mr *MockClientMockRecorder) CountBy(ctx, table, rr, groupFn interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockClient)(nil).Count), ctx, table)
+	varargs := append([]interface{}{ctx, table, rr, groupFn}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountBy", reflect.TypeOf((*MockClient)(nil).CountBy), varargs...)
 }
 
 // Tables mocks base method
@@ -132,3 +191,61 @@ func (mr *MockClientMockRecorder) Tables(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tables", reflect.TypeOf((*MockClient)(nil).Tables), ctx)
 }
+
+// Families mocks base method
+func (m *MockClient) Families(ctx context.Context, table string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Families", ctx, table)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Families indicates an expected call of Families
+func (mr *MockClientMockRecorder) Families(ctx, table interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Families", reflect.TypeOf((*MockClient)(nil).Families), ctx, table)
+}
+
+// ApplyBulk mocks base method
+func (m *MockClient) ApplyBulk(ctx context.Context, table string, keys []string, muts []*bigtable.Mutation) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyBulk", ctx, table, keys, muts)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyBulk indicates an expected call of ApplyBulk
+func (mr *MockClientMockRecorder) ApplyBulk(ctx, table, keys, muts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyBulk", reflect.TypeOf((*MockClient)(nil).ApplyBulk), ctx, table, keys, muts)
+}
+
+// Stats mocks base method
+func (m *MockClient) Stats() Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats
+func (mr *MockClientMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockClient)(nil).Stats))
+}
+
+// Close mocks base method
+func (m *MockClient) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockClientMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClient)(nil).Close))
+}
@@ -181,21 +181,59 @@ func TestCount(t *testing.T) {
 
 	cases := []struct {
 		table  string
+		rr     bigtable.RowRange
+		opts   []bigtable.ReadOption
 		expect int
 	}{
-		{"users", 5},
+		{"users", bigtable.InfiniteRange(""), []bigtable.ReadOption{}, 5},
+		{"users", bigtable.PrefixRange("1"), []bigtable.ReadOption{}, 2},
+		{
+			"users",
+			bigtable.InfiniteRange(""),
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.FamilyFilter("^d'$")),
+			},
+			1,
+		},
 	}
 	for _, c := range cases {
 		r, err := NewClient("test-project", "test-instance")
 		assert.NoError(t, err)
 
-		cnt, err := r.Count(context.Background(), c.table)
+		cnt, err := r.Count(context.Background(), c.table, c.rr, c.opts...)
 		assert.NoError(t, err)
 
 		assert.Equal(t, c.expect, cnt)
 	}
 }
 
+func TestCountBy(t *testing.T) {
+	loadFixture(t, "testdata/articles.yaml")
+
+	cases := []struct {
+		table   string
+		rr      bigtable.RowRange
+		groupFn func(string) string
+		expect  map[string]int
+	}{
+		{
+			"articles",
+			bigtable.InfiniteRange(""),
+			func(key string) string { return key[:1] },
+			map[string]int{"1": 1, "2": 2, "3": 1},
+		},
+	}
+	for _, c := range cases {
+		r, err := NewClient("test-project", "test-instance")
+		assert.NoError(t, err)
+
+		cnts, err := r.CountBy(context.Background(), c.table, c.rr, c.groupFn)
+		assert.NoError(t, err)
+
+		assert.Equal(t, c.expect, cnts)
+	}
+}
+
 func TestTables(t *testing.T) {
 	loadFixture(t, "testdata/users.yaml")
 	loadFixture(t, "testdata/articles.yaml")
@@ -220,3 +258,50 @@ func TestTables(t *testing.T) {
 		assert.Subset(t, tbls, c.expect)
 	}
 }
+
+func TestFamilies(t *testing.T) {
+	loadFixture(t, "testdata/users.yaml")
+
+	cases := []struct {
+		table  string
+		expect []string
+	}{
+		{"users", []string{"d", "d'"}},
+	}
+	for _, c := range cases {
+		r, err := NewClient("test-project", "test-instance")
+		assert.NoError(t, err)
+
+		fams, err := r.Families(context.Background(), c.table)
+		assert.NoError(t, err)
+
+		assert.Equal(t, c.expect, fams)
+	}
+}
+
+func TestApplyBulk(t *testing.T) {
+	loadFixture(t, "testdata/users.yaml")
+
+	r, err := NewClient("test-project", "test-instance")
+	assert.NoError(t, err)
+	defer r.Close()
+
+	version := time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)
+	set := bigtable.NewMutation()
+	set.Set("d", "row", bigtable.Time(version), []byte("nagisa"))
+	del := bigtable.NewMutation()
+	del.DeleteRow()
+	errs, err := r.ApplyBulk(context.Background(), "users", []string{"5", "1"}, []*bigtable.Mutation{set, del})
+	assert.NoError(t, err)
+	assert.Nil(t, errs)
+
+	bt, err := r.GetRows(context.Background(), "users", bigtable.NewRange("1", "6"))
+	assert.NoError(t, err)
+	keys := []string{}
+	for _, row := range bt.Rows {
+		keys = append(keys, row.Key)
+	}
+	assert.Equal(t, []string{"10", "2", "3", "4", "5"}, keys)
+	assert.Equal(t, []byte("nagisa"), bt.Rows[4].Columns[0].Value)
+	assert.Equal(t, version, bt.Rows[4].Columns[0].Version.UTC())
+}
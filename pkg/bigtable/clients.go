@@ -0,0 +1,74 @@
+package bigtable
+
+import (
+	"sync"
+)
+
+// Profile is the project and the instance to connect
+type Profile struct {
+	Project  string
+	Instance string
+}
+
+// Clients holds the clients connected to the multiple instances, to compare or to copy the tables across the instances.
+// The client is connected at the first use, and kept until Close.
+type Clients struct {
+	opts []Option
+
+	mu      sync.Mutex
+	clients map[Profile]Client
+	// connect is replaced in the tests
+	connect func(project, instance string, opts ...Option) (Client, error)
+}
+
+// NewClients returns the clients created with the options.
+func NewClients(opts ...Option) *Clients {
+	return &Clients{
+		opts:    opts,
+		clients: map[Profile]Client{},
+		connect: NewClient,
+	}
+}
+
+// Get returns the client connected to the profile.
+func (cs *Clients) Get(p Profile) (Client, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if c, ok := cs.clients[p]; ok {
+		return c, nil
+	}
+	c, err := cs.connect(p.Project, p.Instance, cs.opts...)
+	if err != nil {
+		return nil, err
+	}
+	cs.clients[p] = c
+	return c, nil
+}
+
+// Stats returns the sum of the statistics of all the clients.
+func (cs *Clients) Stats() Stats {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	var s Stats
+	for _, c := range cs.clients {
+		s = s.Add(c.Stats())
+	}
+	return s
+}
+
+// Close closes all the clients, and returns the first error.
+func (cs *Clients) Close() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	var err error
+	for p, c := range cs.clients {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+		delete(cs.clients, p)
+	}
+	return err
+}
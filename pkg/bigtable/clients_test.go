@@ -0,0 +1,61 @@
+package bigtable
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClients(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	connected := 0
+	cs := NewClients()
+	cs.connect = func(project, instance string, opts ...Option) (Client, error) {
+		if instance == "unknown" {
+			return nil, errors.New("failed to connect")
+		}
+		connected++
+		c := NewMockClient(ctrl)
+		c.EXPECT().Close().Return(nil)
+		return c, nil
+	}
+
+	prod, err := cs.Get(Profile{Project: "p", Instance: "prod"})
+	assert.NoError(t, err)
+	again, err := cs.Get(Profile{Project: "p", Instance: "prod"})
+	assert.NoError(t, err)
+	assert.True(t, prod == again)
+	staging, err := cs.Get(Profile{Project: "p", Instance: "staging"})
+	assert.NoError(t, err)
+	assert.False(t, prod == staging)
+	assert.Equal(t, 2, connected)
+
+	_, err = cs.Get(Profile{Project: "p", Instance: "unknown"})
+	assert.Error(t, err)
+
+	assert.NoError(t, cs.Close())
+	assert.Empty(t, cs.clients)
+}
+
+func TestClientsStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cs := NewClients()
+	cs.connect = func(project, instance string, opts ...Option) (Client, error) {
+		c := NewMockClient(ctrl)
+		c.EXPECT().Stats().Return(Stats{Rows: 1, Cells: 2, Bytes: 100}).AnyTimes()
+		return c, nil
+	}
+	assert.Equal(t, Stats{}, cs.Stats())
+
+	_, err := cs.Get(Profile{Project: "p", Instance: "prod"})
+	assert.NoError(t, err)
+	_, err = cs.Get(Profile{Project: "p", Instance: "staging"})
+	assert.NoError(t, err)
+	assert.Equal(t, Stats{Rows: 2, Cells: 4, Bytes: 200}, cs.Stats())
+}
@@ -0,0 +1,173 @@
+package bigtable
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// ShardRanges splits the range from begin to end by the sampled row keys.
+// Empty end means infinity.
+func ShardRanges(keys []string, begin, end string) []bigtable.RowRange {
+	sorted := make([]string, len(keys))
+	copy(sorted, keys)
+	sort.Strings(sorted)
+
+	bounds := []string{begin}
+	for _, k := range sorted {
+		if k <= bounds[len(bounds)-1] || (end != "" && k >= end) {
+			continue
+		}
+		bounds = append(bounds, k)
+	}
+
+	rrs := make([]bigtable.RowRange, 0, len(bounds))
+	for i, b := range bounds {
+		switch {
+		case i+1 < len(bounds):
+			rrs = append(rrs, bigtable.NewRange(b, bounds[i+1]))
+		case end == "":
+			rrs = append(rrs, bigtable.InfiniteRange(b))
+		default:
+			rrs = append(rrs, bigtable.NewRange(b, end))
+		}
+	}
+	return rrs
+}
+
+// ParallelScan reads the rows from begin to end with the workers up to parallelism.
+// The range is split into shards by SampleRowKeys, and fn is invoked concurrently with the shard index.
+// Stops all workers when fn returns false.
+func ParallelScan(ctx context.Context, c Client, table, begin, end string, parallelism int, fn func(shard int, r *Row) bool, opts ...bigtable.ReadOption) error {
+	keys, err := c.SampleRowKeys(ctx, table)
+	if err != nil {
+		return err
+	}
+	return scanShards(ctx, c, table, ShardRanges(keys, begin, end), parallelism, fn, nil, opts...)
+}
+
+// orderedBufferSize is the number of the rows buffered per shard by ParallelScanOrdered
+const orderedBufferSize = 1000
+
+// ParallelScanOrdered reads the rows like ParallelScan, and invokes fn in the key order on the calling goroutine.
+// The workers ahead of the current shard are blocked when orderedBufferSize rows are buffered.
+// Stops at the first shard not read to the end, so the rows passed to fn have no gaps.
+func ParallelScanOrdered(ctx context.Context, c Client, table, begin, end string, parallelism int, fn func(r *Row) bool, opts ...bigtable.ReadOption) error {
+	keys, err := c.SampleRowKeys(ctx, table)
+	if err != nil {
+		return err
+	}
+	shards := ShardRanges(keys, begin, end)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rows := make([]chan *Row, len(shards))
+	for i := range rows {
+		rows[i] = make(chan *Row, orderedBufferSize)
+	}
+	// errs is the error of the shard, written before its channel is closed
+	errs := make([]error, len(shards))
+	scanErr := make(chan error, 1)
+	go func() {
+		scanErr <- scanShards(ctx, c, table, shards, parallelism, func(shard int, r *Row) bool {
+			select {
+			case rows[shard] <- r:
+				return true
+			case <-ctx.Done():
+				errs[shard] = ctx.Err()
+				return false
+			}
+		}, func(shard int, err error) {
+			if err != nil {
+				errs[shard] = err
+			}
+			close(rows[shard])
+		}, opts...)
+	}()
+
+	for i, ch := range rows {
+		for r := range ch {
+			if !fn(r) {
+				cancel()
+				<-scanErr
+				return nil
+			}
+		}
+		if errs[i] != nil {
+			cancel()
+			if err := <-scanErr; err != nil {
+				return err
+			}
+			return errs[i]
+		}
+	}
+	return <-scanErr
+}
+
+// scanShards reads the shards with the workers up to parallelism, the shards are dispatched in order.
+// done is called with the error after each shard is read, and also for the shards not dispatched by the stop.
+func scanShards(ctx context.Context, c Client, table string, shards []bigtable.RowRange, parallelism int, fn func(shard int, r *Row) bool, done func(shard int, err error), opts ...bigtable.ReadOption) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		stopOnce sync.Once
+		firstErr error
+	)
+	stop := func(err error) {
+		stopOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	idx := make(chan int)
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for shard := range idx {
+				err := c.ReadRows(ctx, table, shards[shard], func(r *Row) bool {
+					if !fn(shard, r) {
+						stop(nil)
+						return false
+					}
+					return true
+				}, opts...)
+				if err != nil {
+					stop(err)
+				}
+				if done != nil {
+					done(shard, err)
+				}
+			}
+		}()
+	}
+
+	next := 0
+dispatch:
+	for ; next < len(shards); next++ {
+		select {
+		case idx <- next:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(idx)
+	if done != nil {
+		for ; next < len(shards); next++ {
+			done(next, ctx.Err())
+		}
+	}
+	wg.Wait()
+
+	return firstErr
+}
@@ -0,0 +1,137 @@
+package bigtable
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardRanges(t *testing.T) {
+	cases := []struct {
+		keys   []string
+		begin  string
+		end    string
+		expect []bigtable.RowRange
+	}{
+		{
+			[]string{},
+			"",
+			"",
+			[]bigtable.RowRange{
+				bigtable.InfiniteRange(""),
+			},
+		},
+		{
+			[]string{"b", "d", ""},
+			"",
+			"",
+			[]bigtable.RowRange{
+				bigtable.NewRange("", "b"),
+				bigtable.NewRange("b", "d"),
+				bigtable.InfiniteRange("d"),
+			},
+		},
+		{
+			// out of the range keys are ignored
+			[]string{"a", "c", "e", "g"},
+			"b",
+			"f",
+			[]bigtable.RowRange{
+				bigtable.NewRange("b", "c"),
+				bigtable.NewRange("c", "e"),
+				bigtable.NewRange("e", "f"),
+			},
+		},
+	}
+	for _, c := range cases {
+		actual := ShardRanges(c.keys, c.begin, c.end)
+		assert.Equal(t, c.expect, actual)
+	}
+}
+
+func TestParallelScan(t *testing.T) {
+	loadFixture(t, "testdata/users.yaml")
+
+	cases := []struct {
+		table  string
+		begin  string
+		end    string
+		expect []string
+	}{
+		{"users", "", "", []string{"1", "10", "2", "3", "4"}},
+		{"users", "1", "3", []string{"1", "10", "2"}},
+	}
+	for _, c := range cases {
+		r, err := NewClient("test-project", "test-instance")
+		assert.NoError(t, err)
+
+		var (
+			mu   sync.Mutex
+			keys []string
+		)
+		err = ParallelScan(context.Background(), r, c.table, c.begin, c.end, 2, func(_ int, row *Row) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			keys = append(keys, row.Key)
+			return true
+		})
+		assert.NoError(t, err)
+
+		sort.Strings(keys)
+		assert.Equal(t, c.expect, keys)
+	}
+}
+
+func TestParallelScanOrdered(t *testing.T) {
+	keys := []string{"1", "10", "2", "3", "4", "5"}
+	cases := []struct {
+		failAt string
+		stopAt string
+		expect []string
+	}{
+		{expect: keys},
+		{stopAt: "3", expect: []string{"1", "10", "2", "3"}},
+		// the rows after the failed shard are not passed
+		{failAt: "2", expect: []string{"1", "10"}},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+
+		mockClient := NewMockClient(ctrl)
+		mockClient.EXPECT().SampleRowKeys(gomock.Any(), "users").Return([]string{"2", "4"}, nil)
+		mockClient.EXPECT().ReadRows(gomock.Any(), "users", gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, rr bigtable.RowRange, fn func(*Row) bool, _ ...bigtable.ReadOption) error {
+				for _, k := range keys {
+					if !rr.Contains(k) {
+						continue
+					}
+					if k == c.failAt {
+						return errors.New("unavailable")
+					}
+					if !fn(&Row{Key: k}) {
+						return nil
+					}
+				}
+				return nil
+			}).AnyTimes()
+
+		var actual []string
+		err := ParallelScanOrdered(context.Background(), mockClient, "users", "", "", 3, func(r *Row) bool {
+			actual = append(actual, r.Key)
+			return r.Key != c.stopAt
+		})
+		if c.failAt != "" {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, c.expect, actual)
+		ctrl.Finish()
+	}
+}
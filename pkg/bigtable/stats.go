@@ -0,0 +1,115 @@
+package bigtable
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"cloud.google.com/go/bigtable"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// Stats is the statistics of the requests
+type Stats struct {
+	// Rows and Cells are the number of the returned rows and cells
+	Rows  int64
+	Cells int64
+	// Bytes is the size of the received messages
+	Bytes int64
+	// Retries is the number of the attempts retried by the client library
+	Retries int64
+}
+
+// Add returns the sum of the statistics.
+func (s Stats) Add(other Stats) Stats {
+	return Stats{
+		Rows:    s.Rows + other.Rows,
+		Cells:   s.Cells + other.Cells,
+		Bytes:   s.Bytes + other.Bytes,
+		Retries: s.Retries + other.Retries,
+	}
+}
+
+// Sub returns the statistics since prev.
+func (s Stats) Sub(prev Stats) Stats {
+	return Stats{
+		Rows:    s.Rows - prev.Rows,
+		Cells:   s.Cells - prev.Cells,
+		Bytes:   s.Bytes - prev.Bytes,
+		Retries: s.Retries - prev.Retries,
+	}
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("rows: %d, cells: %d, bytes: %d, retries: %d", s.Rows, s.Cells, s.Bytes, s.Retries)
+}
+
+// recorder collects the statistics of the client.
+// It's the gRPC stats handler to count the received bytes and the retried attempts.
+type recorder struct {
+	rows    int64
+	cells   int64
+	bytes   int64
+	retries int64
+}
+
+type attemptsKey struct{}
+
+// withAttempts returns the context to count the attempts of the request.
+// The attempts after the first one are recorded as the retries.
+func withAttempts(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptsKey{}, new(int64))
+}
+
+func (r *recorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *recorder) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	switch s := s.(type) {
+	case *stats.Begin:
+		if n, ok := ctx.Value(attemptsKey{}).(*int64); ok && atomic.AddInt64(n, 1) > 1 {
+			atomic.AddInt64(&r.retries, 1)
+		}
+	case *stats.InPayload:
+		atomic.AddInt64(&r.bytes, int64(s.Length))
+	}
+}
+
+func (r *recorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *recorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func (r *recorder) addRow(row bigtable.Row) {
+	atomic.AddInt64(&r.rows, 1)
+	for _, items := range row {
+		atomic.AddInt64(&r.cells, int64(len(items)))
+	}
+}
+
+func (r *recorder) stats() Stats {
+	return Stats{
+		Rows:    atomic.LoadInt64(&r.rows),
+		Cells:   atomic.LoadInt64(&r.cells),
+		Bytes:   atomic.LoadInt64(&r.bytes),
+		Retries: atomic.LoadInt64(&r.retries),
+	}
+}
+
+// clientOptions returns the options to record the statistics of the connection.
+func (r *recorder) clientOptions() ([]option.ClientOption, error) {
+	// the library dials the emulator without the dial options
+	if addr := os.Getenv("BIGTABLE_EMULATOR_HOST"); addr != "" {
+		conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithStatsHandler(r))
+		if err != nil {
+			return nil, fmt.Errorf("emulator grpc.Dial: %v", err)
+		}
+		return []option.ClientOption{option.WithGRPCConn(conn)}, nil
+	}
+	return []option.ClientOption{option.WithGRPCDialOption(grpc.WithStatsHandler(r))}, nil
+}
@@ -0,0 +1,37 @@
+package bigtable
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	loadFixture(t, "testdata/users.yaml")
+
+	cases := []struct {
+		rr          bigtable.RowRange
+		expectRows  int64
+		expectCells int64
+	}{
+		{bigtable.InfiniteRange(""), 5, 6},
+		{bigtable.PrefixRange("4"), 1, 2},
+	}
+	for _, c := range cases {
+		r, err := NewClient("test-project", "test-instance")
+		assert.NoError(t, err)
+
+		before := r.Stats()
+		_, err = r.GetRows(context.Background(), "users", c.rr)
+		assert.NoError(t, err)
+
+		s := r.Stats().Sub(before)
+		assert.Equal(t, c.expectRows, s.Rows)
+		assert.Equal(t, c.expectCells, s.Cells)
+		assert.True(t, s.Bytes > 0)
+		assert.Equal(t, int64(0), s.Retries)
+		r.Close()
+	}
+}
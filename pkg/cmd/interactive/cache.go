@@ -0,0 +1,74 @@
+package interactive
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	prompt "github.com/c-bata/go-prompt"
+	"github.com/takashabe/btcli/pkg/bigtable"
+)
+
+// metadataTTL is the lifetime of the cached metadata.
+const metadataTTL = 5 * time.Minute
+
+// metadata caches the suggestions of the tables, families, columns and row keys for the completer.
+var metadata = newMetadataCache(metadataTTL)
+
+// metadataCache caches the suggestions with TTL
+type metadataCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	suggests []prompt.Suggest
+	expire   time.Time
+}
+
+func newMetadataCache(ttl time.Duration) *metadataCache {
+	return &metadataCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]*cacheEntry{},
+	}
+}
+
+// get returns the cached suggestions, or the loaded suggestions when not cached or expired.
+// Errors are not cached.
+func (m *metadataCache) get(key string, load func() ([]prompt.Suggest, error)) ([]prompt.Suggest, error) {
+	m.mu.Lock()
+	e, ok := m.entries[key]
+	m.mu.Unlock()
+	if ok && m.now().Before(e.expire) {
+		return e.suggests, nil
+	}
+
+	ss, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.entries[key] = &cacheEntry{
+		suggests: ss,
+		expire:   m.now().Add(m.ttl),
+	}
+	m.mu.Unlock()
+	return ss, nil
+}
+
+// clear removes all the cached suggestions.
+func (m *metadataCache) clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = map[string]*cacheEntry{}
+}
+
+func doRefresh(ctx context.Context, client bigtable.Client, args ...string) error {
+	metadata.clear()
+	return nil
+}
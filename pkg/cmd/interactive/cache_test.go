@@ -0,0 +1,52 @@
+package interactive
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	prompt "github.com/c-bata/go-prompt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataCache(t *testing.T) {
+	now := time.Now()
+	cache := newMetadataCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	loaded := 0
+	load := func() ([]prompt.Suggest, error) {
+		loaded++
+		return []prompt.Suggest{{Text: "users"}}, nil
+	}
+	expect := []prompt.Suggest{{Text: "users"}}
+
+	// cached within TTL
+	for i := 0; i < 2; i++ {
+		ss, err := cache.get("tables", load)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, ss)
+	}
+	assert.Equal(t, 1, loaded)
+
+	// expired
+	now = now.Add(time.Minute)
+	_, err := cache.get("tables", load)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, loaded)
+
+	// cleared
+	cache.clear()
+	_, err = cache.get("tables", load)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, loaded)
+
+	// errors are not cached
+	_, err = cache.get("families/users", func() ([]prompt.Suggest, error) {
+		return nil, errors.New("unavailable")
+	})
+	assert.Error(t, err)
+	ss, err := cache.get("families/users", load)
+	assert.NoError(t, err)
+	assert.Equal(t, expect, ss)
+}
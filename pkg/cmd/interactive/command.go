@@ -8,12 +8,54 @@ import (
 	"github.com/takashabe/btcli/pkg/evaluator/cbt"
 )
 
+// positional argument types for the completion
+const (
+	argCommand = "command"
+	argTable   = "table"
+	argRow     = "row"
+	argQuery   = "query"
+	argSetting = "setting"
+	argSwitch  = "switch"
+	// argInterval is not completed
+	argInterval = "interval"
+)
+
 // Command defines command describe and runner
 type Command struct {
 	Name        string
 	Description string
 	Usage       string
-	Runner      func(context.Context, bigtable.Client, ...string)
+	Runner      func(context.Context, bigtable.Client, ...string) error
+
+	// Args is the types of the positional arguments
+	Args []string
+	// Options is the "key=value" options that following the positional arguments
+	Options cbt.Options
+
+	// DefaultTable represents the table of the first argument may be omitted, and filled with the default table
+	DefaultTable bool
+
+	// Admin represents the command changes tables, families or the cells, and invalidates the cached metadata
+	Admin bool
+
+	// Rows represents the command prints the rows, and watch compares the cells
+	Rows bool
+	// Continuous represents the command runs until Ctrl-C, the output is not paged and can not be watched
+	Continuous bool
+	// Reads represents the command only reads the tables, and can be watched as well as Rows
+	Reads bool
+	// Writes represents the command writes the rows, the file or the library, and can not be watched
+	Writes bool
+	// Prompts reports whether the command waits for the answer on the terminal with the arguments, then the output is not paged
+	Prompts func(args []string) bool
+}
+
+// FullUsage returns the usage with the description of the options.
+func (c Command) FullUsage() string {
+	if len(c.Options) == 0 {
+		return c.Usage
+	}
+	return c.Usage + "\n" + c.Options.Usage()
 }
 
 var commands = []Command{
@@ -22,60 +64,215 @@ var commands = []Command{
 		Description: "help command",
 		Usage:       "help [<command>]",
 		Runner:      doHelp,
+		Args:        []string{argCommand},
 	},
 	{
 		Name:        "ls",
 		Description: "List tables",
 		Usage:       "ls",
 		Runner:      cbt.DoLS,
+		Reads:       true,
+	},
+	{
+		Name:         "count",
+		Description:  "Count table rows",
+		Usage:        "count <table> [start=<row>] [end=<row>] [prefix=<prefix>] [family=<column_family>] [by-prefix=<n>]",
+		Runner:       cbt.DoCount,
+		Args:         []string{argTable},
+		Options:      cbt.CountOptions,
+		DefaultTable: true,
+		Reads:        true,
+	},
+	{
+		Name:         "lookup",
+		Description:  "Read from a single row",
+		Usage:        "lookup <table> <row> [family=<column_family>] [version=<n>]",
+		Runner:       cbt.DoLookup,
+		Args:         []string{argTable, argRow},
+		Options:      cbt.LookupOptions,
+		DefaultTable: true,
+		Rows:         true,
+	},
+	{
+		Name:         "read",
+		Description:  "Read from a multi rows",
+		Usage:        "read <table> [start=<row>] [end=<row>] [prefix=<prefix>] [family=<column_family>] [version=<n>]",
+		Runner:       cbt.DoRead,
+		Args:         []string{argTable},
+		Options:      cbt.ReadOptions,
+		DefaultTable: true,
+		Rows:         true,
+		Prompts:      readPrompts,
+	},
+	{
+		Name:         "stats",
+		Description:  "Print statistics of the table",
+		Usage:        "stats <table> [start=<row>] [end=<row>] [prefix=<prefix>] [family=<column_family>] [sample=<ratio>] [top=<n>]",
+		Runner:       cbt.DoStats,
+		Args:         []string{argTable},
+		Options:      cbt.StatsOptions,
+		DefaultTable: true,
+		Reads:        true,
+	},
+	{
+		Name:        "next",
+		Description: "Read the next rows of the last read",
+		Usage:       "next",
+		Runner:      cbt.DoNext,
+	},
+	{
+		Name:        "diff",
+		Description: "Compare the cells of two rows, or the rows of two tables",
+		Usage:       "diff <table> <row> <table> <row> [family=<column_family>] [version=<n>] | diff <table> <table> [start=<row>] [end=<row>] [prefix=<prefix>]",
+		Runner:      cbt.DoDiff,
+		Args:        []string{argTable},
+		Options:     cbt.DiffOptions,
 	},
 	{
-		Name:        "count",
-		Description: "Count table rows",
-		Usage:       "count <table>",
-		Runner:      cbt.DoCount,
-	},
-	{
-		Name:        "lookup",
-		Description: "Read from a single row",
-		Usage: `lookup <table> <row> [family=<column_family>] [version=<n>]
-	version        Read only latest <n> columns
-	decode         Decode big-endian value
-	decode-columns Decode big-endian value with columns. <column_name:<string|int|float>[,<column_name:...>]`,
-		Runner: cbt.DoLookup,
-	},
-	{
-		Name:        "read",
-		Description: "Read from a multi rows",
-		Usage: `read <table> [start=<row>] [end=<row>] [prefix=<prefix>] [family=<column_family>] [version=<n>]
-	start          Start reading at this row
-	end            Stop reading before this row
-	prefix         Read rows with this prefix
-	value          Read rows with has value
-	family         Read only columns family with <columns_family>
-	version        Read only latest <n> columns
-	from           Read newer cells than this unixtime
-	to             Read older cells than this unittime
-	decode         Decode big-endian value
-	decode-columns Decode big-endian value with columns. <column_name:<string|int|float>[,<column_name:...>]`,
-		Runner: cbt.DoRead,
+		Name:        "sync",
+		Description: "Apply the mutations to make the rows of the destination table match the source table",
+		Usage:       "sync <src-table> <dst-table> [start=<row>] [end=<row>] [prefix=<prefix>] [dry-run=true] [yes=true]",
+		Runner:      cbt.DoSync,
+		Args:        []string{argTable},
+		Options:     cbt.SyncOptions,
+		Admin:       true,
+		Writes:      true,
+		Prompts:     syncPrompts,
+	},
+	{
+		Name:        "copy",
+		Description: "Copy the rows to the other table",
+		Usage:       "copy <src-table> <dst-table> [start=<row>] [end=<row>] [prefix=<prefix>] [rename-family=<from>:<to>] [key-transform=s/<regex>/<replacement>/]",
+		Runner:      cbt.DoCopy,
+		Args:        []string{argTable},
+		Options:     cbt.CopyOptions,
+		Admin:       true,
+		Writes:      true,
+	},
+	{
+		Name:        "export",
+		Description: "Write the rows to the file as JSON Lines or CSV",
+		Usage:       "export <table> <file> [format=<jsonl|csv>] [start=<row>] [end=<row>] [prefix=<prefix>] [parallelism=<n>] [resume=true]",
+		Runner:      cbt.DoExport,
+		Args:        []string{argTable},
+		Options:     cbt.ExportOptions,
+		Writes:      true,
+	},
+	{
+		Name:         "tail",
+		Description:  "Print the newly written cells until Ctrl-C",
+		Usage:        "tail <table> [prefix=<prefix>] [family=<column_family>] [interval=<duration>]",
+		Runner:       cbt.DoTail,
+		Args:         []string{argTable},
+		Options:      cbt.TailOptions,
+		DefaultTable: true,
+		Continuous:   true,
+	},
+	{
+		Name:        "watch",
+		Description: "Run the command every interval and highlight the changed cells until Ctrl-C",
+		Usage:       "watch <interval> <command> [args ...]",
+		Runner:      doWatch,
+		Args:        []string{argInterval, argCommand},
+		Continuous:  true,
 	},
 
 	// btcli commands
+	{
+		Name:        "let",
+		Description: "Set the session variables",
+		Usage:       "let [<name>=<value> ...]",
+		Runner:      doLet,
+	},
+	{
+		Name:        "use",
+		Description: "Set the default table",
+		Usage:       "use [<table>]",
+		Runner:      doUse,
+		Args:        []string{argTable},
+	},
+	{
+		Name:        "set",
+		Description: "Turn on or off the settings",
+		Usage:       "set [<pager|timing> <on|off>]",
+		Runner:      doSet,
+		Args:        []string{argSetting, argSwitch},
+	},
+	{
+		Name:        "alias",
+		Description: "Set the command aliases",
+		Usage:       "alias [<name>='<command> [args ...]']",
+		Runner:      doAlias,
+		Writes:      true,
+	},
+	{
+		Name:        "save",
+		Description: "Save the command as the named query",
+		Usage:       "save query <name> <command> [args ...]",
+		Runner:      doSave,
+		Writes:      true,
+	},
+	{
+		Name:        "run",
+		Description: "Run the saved query",
+		Usage:       "run [<name> [args ...]]",
+		Runner:      doRun,
+		Args:        []string{argQuery},
+	},
+	{
+		Name:        "profile",
+		Description: "Set the profiles to refer the tables of the other instances as <profile>:<table>",
+		Usage:       "profile [<name> [instance=<instance>] [project=<project>]]",
+		Runner:      doProfile,
+		Writes:      true,
+	},
+	{
+		Name:        "refresh",
+		Description: "Clear the cached tables, families, columns and row keys for the completion",
+		Usage:       "refresh",
+		Runner:      doRefresh,
+	},
 	{
 		Name:        "exit",
 		Description: "Exit this prompt",
-		Usage:       "Exit this prompt",
+		Usage:       "exit [<code>]",
 		Runner:      doExit,
 	},
 	{
 		Name:        "quit",
 		Description: "Exit this prompt",
-		Usage:       "Exit this prompt",
+		Usage:       "quit [<code>]",
 		Runner:      doExit,
 	},
 }
 
+// readPrompts reports whether the read waits for the next page.
+func readPrompts(args []string) bool {
+	if len(args) < 1 {
+		return false
+	}
+	parsed, err := cbt.ReadOptions.Parse(args[1:])
+	return err == nil && parsed.Int("page-size") > 0
+}
+
+// syncPrompts reports whether the sync confirms before applying the mutations.
+func syncPrompts(args []string) bool {
+	if len(args) < 2 {
+		return false
+	}
+	parsed, err := cbt.SyncOptions.Parse(args[2:])
+	return err == nil && !parsed.Bool("dry-run") && !parsed.Bool("yes")
+}
+
+func findCommand(name string) (Command, bool) {
+	for _, c := range commands {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
 func getAllSuggests() []prompt.Suggest {
 	ss := make([]prompt.Suggest, 0, len(commands))
 	for _, c := range commands {
@@ -2,15 +2,35 @@ package interactive
 
 import (
 	"context"
+	"sort"
 	"strings"
+	"time"
 
+	"cloud.google.com/go/bigtable"
 	prompt "github.com/c-bata/go-prompt"
-	"github.com/takashabe/btcli/pkg/bigtable"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+	"github.com/takashabe/btcli/pkg/printer"
+)
+
+const (
+	// completionWordSeparator separates the word to be replaced by the suggestion.
+	completionWordSeparator = " =,:"
+
+	// keySampleRows is the number of row keys to suggest.
+	keySampleRows = 20
+	// keySampleTimeout is the timeout to read the row keys for the completion.
+	keySampleTimeout = 500 * time.Millisecond
+
+	// columnSampleRows is the number of rows to find the column names for the completion.
+	columnSampleRows = 10
 )
 
 // Completer provides completion command handler
 type Completer struct {
-	client bigtable.Client
+	client bt.Client
+	// cache caches the metadata suggestions, disabled when nil
+	cache *metadataCache
 }
 
 // Do provide completion to prompt
@@ -25,55 +45,121 @@ func (c *Completer) Do(d prompt.Document) []prompt.Suggest {
 
 func (c *Completer) completeWithArguments(args ...string) []prompt.Suggest {
 	if len(args) <= 1 {
-		return prompt.FilterHasPrefix(getAllSuggests(), args[0], true)
+		return prompt.FilterHasPrefix(append(getAllSuggests(), getAliasSuggestions()...), args[0], true)
+	}
+	if cmd, ok := library.Aliases[args[0]]; ok {
+		args = append(strings.Split(cmd, " "), args[1:]...)
 	}
 
-	cmd := args[0]
-
-	second := args[1]
-	switch cmd {
-	case "count":
-		if len(args) == 2 {
-			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
-		}
-	case "lookup":
-		if len(args) == 2 {
-			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		return []prompt.Suggest{}
+	}
+	latest := args[len(args)-1]
+	if word := latest[strings.LastIndexAny(latest, completionWordSeparator)+1:]; strings.HasPrefix(word, "$") {
+		return prompt.FilterHasPrefix(getVariableSuggestions(), word, true)
+	}
+	for _, a := range args[:len(args)-1] {
+		// the file or the shell command is not completed
+		if a == ">" || a == ">>" || a == "|" {
+			return []prompt.Suggest{}
 		}
+	}
+	// the rest of the arguments are the other command, e.g. "watch 5s read ..."
+	if n := len(cmd.Args); n > 0 && cmd.Args[n-1] == argCommand && len(args) > n+1 {
+		return c.completeWithArguments(args[n:]...)
+	}
+	args = completedArgs(cmd, args)
 
-		subcommands := []prompt.Suggest{
-			{Text: "version"},
-		}
-		if len(args) > 3 {
-			distinctCommands := filterDuplicateCommands(args, subcommands)
-			latestCmd := args[len(args)-1]
-			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
-		}
-	case "read":
-		if len(args) == 2 {
-			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+	// positional arguments
+	if pos := len(args) - 2; pos < len(cmd.Args) {
+		switch cmd.Args[pos] {
+		case argCommand:
+			return prompt.FilterHasPrefix(getAllSuggests(), latest, true)
+		case argTable:
+			// the word after ":" is replaced, as "<profile>:<table>"
+			if i := strings.Index(latest, ":"); i >= 0 {
+				return prompt.FilterHasPrefix(c.getProfileTableSuggestions(latest[:i]), latest[i+1:], true)
+			}
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), latest, true)
+		case argRow:
+			return c.getRowKeySuggestions(args[1], latest)
+		case argQuery:
+			return prompt.FilterHasPrefix(getQuerySuggestions(), latest, true)
+		case argSetting:
+			return prompt.FilterHasPrefix(getSettingSuggestions(), latest, true)
+		case argSwitch:
+			return prompt.FilterHasPrefix([]prompt.Suggest{{Text: "on"}, {Text: "off"}}, latest, true)
 		}
+		return []prompt.Suggest{}
+	}
+
+	// options
+	if len(cmd.Options) == 0 {
+		return []prompt.Suggest{}
+	}
+	if ss, ok := c.completeValue(args[1], cmd.Options, latest); ok {
+		return ss
+	}
+	subcommands := make([]prompt.Suggest, 0, len(cmd.Options))
+	for _, o := range cmd.Options {
+		subcommands = append(subcommands, prompt.Suggest{Text: o.Name, Description: o.Description})
+	}
+	distinctCommands := filterDuplicateCommands(args, subcommands)
+	return prompt.FilterHasPrefix(distinctCommands, latest, true)
+}
+
+// completeValue provides completion for the value of the "key=value" argument by the option spec.
+// Returns false when the argument is not a "key=value" form.
+func (c *Completer) completeValue(table string, opts cbt.Options, arg string) ([]prompt.Suggest, bool) {
+	i := strings.Index(arg, "=")
+	if i < 0 {
+		return nil, false
+	}
+	key, val := arg[:i], arg[i+1:]
+	o, ok := opts.Lookup(key)
+	if !ok {
+		return []prompt.Suggest{}, true
+	}
 
-		subcommands := []prompt.Suggest{
-			{Text: "start"},
-			{Text: "end"},
-			{Text: "prefix"},
-			{Text: "family"},
-			{Text: "value"},
-			{Text: "version"},
-			{Text: "from"},
-			{Text: "to"},
-			{Text: "decode"},
-			{Text: "decode-columns"},
+	var ss []prompt.Suggest
+	switch o.Complete {
+	case cbt.CompleteFamily:
+		ss = c.getFamilySuggestions(table)
+	case cbt.CompleteRowKey:
+		return c.getRowKeySuggestions(table, val), true
+	case cbt.CompleteDecodeColumns:
+		// format: "column1:type1,column2:type2,..."
+		val = val[strings.LastIndex(val, ",")+1:]
+		if j := strings.Index(val, ":"); j >= 0 {
+			val = val[j+1:]
+			ss = getDecodeTypeSuggestions()
+		} else {
+			ss = c.getColumnSuggestions(table)
 		}
-		if len(args) > 2 {
-			distinctCommands := filterDuplicateCommands(args, subcommands)
-			latestCmd := args[len(args)-1]
-			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	default:
+		for _, v := range o.Candidates() {
+			ss = append(ss, prompt.Suggest{Text: v})
 		}
 	}
+	if len(ss) == 0 {
+		return []prompt.Suggest{}, true
+	}
+	return prompt.FilterHasPrefix(ss, val, true), true
+}
 
-	return []prompt.Suggest{}
+// completedArgs returns the arguments as the executor runs, expanded the variables and filled the default table.
+// The latest word is excluded from deciding whether the table is omitted, because it is not completed yet.
+func completedArgs(cmd Command, args []string) []string {
+	ret := []string{args[0]}
+	for _, a := range args[1 : len(args)-1] {
+		if e, err := currentSession.expand(a); err == nil {
+			a = e
+		}
+		ret = append(ret, a)
+	}
+	ret = append(ret[:1], currentSession.withTable(cmd, ret[1:])...)
+	return append(ret, args[len(args)-1])
 }
 
 func filterDuplicateCommands(args []string, subcommands []prompt.Suggest) []prompt.Suggest {
@@ -81,7 +167,8 @@ func filterDuplicateCommands(args []string, subcommands []prompt.Suggest) []prom
 	for _, s := range subcommands {
 		exist := false
 		for _, a := range args {
-			if strings.HasPrefix(a, s.Text) {
+			// compare with the key of "key=value"
+			if strings.SplitN(a, "=", 2)[0] == s.Text {
 				exist = true
 				break
 			}
@@ -93,15 +180,211 @@ func filterDuplicateCommands(args []string, subcommands []prompt.Suggest) []prom
 	return ret
 }
 
-func (c *Completer) getTableSuggestions() []prompt.Suggest {
-	tbls, err := c.client.Tables(context.Background())
+// cached returns the suggestions through the cache.
+func (c *Completer) cached(key string, load func() ([]prompt.Suggest, error)) []prompt.Suggest {
+	var (
+		ss  []prompt.Suggest
+		err error
+	)
+	if c.cache == nil {
+		ss, err = load()
+	} else {
+		ss, err = c.cache.get(key, load)
+	}
 	if err != nil {
 		return []prompt.Suggest{}
 	}
+	return ss
+}
+
+func (c *Completer) getTableSuggestions() []prompt.Suggest {
+	return c.cached("tables", func() ([]prompt.Suggest, error) {
+		return loadTableSuggestions(c.client)
+	})
+}
+
+// getProfileTableSuggestions returns the tables of the instance of the named profile.
+func (c *Completer) getProfileTableSuggestions(name string) []prompt.Suggest {
+	if cbt.ConnectProfile == nil {
+		return []prompt.Suggest{}
+	}
+	return c.cached("tables/"+name, func() ([]prompt.Suggest, error) {
+		client, err := cbt.ConnectProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		return loadTableSuggestions(client)
+	})
+}
+
+func loadTableSuggestions(client bt.Client) ([]prompt.Suggest, error) {
+	tbls, err := client.Tables(context.Background())
+	if err != nil {
+		return nil, err
+	}
 
 	s := make([]prompt.Suggest, 0, len(tbls))
 	for _, t := range tbls {
 		s = append(s, prompt.Suggest{Text: t})
 	}
+	return s, nil
+}
+
+// tableClient returns the client and the table name, connected to the profile for "<profile>:<table>".
+func (c *Completer) tableClient(table string) (bt.Client, string, error) {
+	i := strings.Index(table, ":")
+	if i < 0 {
+		return c.client, table, nil
+	}
+	if cbt.ConnectProfile == nil {
+		return nil, "", cbt.UsageError("Profiles are not available: %s", table)
+	}
+	client, err := cbt.ConnectProfile(table[:i])
+	if err != nil {
+		return nil, "", err
+	}
+	return client, table[i+1:], nil
+}
+
+func (c *Completer) getFamilySuggestions(table string) []prompt.Suggest {
+	return c.cached("families/"+table, func() ([]prompt.Suggest, error) {
+		client, table, err := c.tableClient(table)
+		if err != nil {
+			return nil, err
+		}
+		fams, err := client.Families(context.Background(), table)
+		if err != nil {
+			return nil, err
+		}
+
+		s := make([]prompt.Suggest, 0, len(fams))
+		for _, f := range fams {
+			s = append(s, prompt.Suggest{Text: f})
+		}
+		return s, nil
+	})
+}
+
+// getColumnSuggestions returns the column names that found in the sampled rows.
+func (c *Completer) getColumnSuggestions(table string) []prompt.Suggest {
+	return c.cached("columns/"+table, func() ([]prompt.Suggest, error) {
+		return c.loadColumnSuggestions(table)
+	})
+}
+
+func (c *Completer) loadColumnSuggestions(table string) ([]prompt.Suggest, error) {
+	client, table, err := c.tableClient(table)
+	if err != nil {
+		return nil, err
+	}
+	b, err := client.GetRows(context.Background(), table, bigtable.InfiniteRange(""),
+		bigtable.LimitRows(columnSampleRows),
+		bigtable.RowFilter(bigtable.StripValueFilter()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := []prompt.Suggest{}
+	exists := map[string]bool{}
+	for _, r := range b.Rows {
+		for _, col := range r.Columns {
+			// qualifier format: "columnFamily:columnName"
+			name := col.Qualifier[strings.Index(col.Qualifier, ":")+1:]
+			if exists[name] {
+				continue
+			}
+			exists[name] = true
+			s = append(s, prompt.Suggest{Text: name, Description: col.Family})
+		}
+	}
+	sort.Slice(s, func(i, j int) bool {
+		return s[i].Text < s[j].Text
+	})
+	return s, nil
+}
+
+// getRowKeySuggestions returns the row keys that start with the prefix, cached for each prefix not to read the table on every key input.
+// The suggestion text is trimmed to after the last separator, because the prompt only replaces the word after it.
+func (c *Completer) getRowKeySuggestions(table, prefix string) []prompt.Suggest {
+	keys := c.cached("rows/"+table+"/"+prefix, func() ([]prompt.Suggest, error) {
+		return c.loadRowKeySuggestions(table, prefix)
+	})
+
+	cut := strings.LastIndexAny(prefix, completionWordSeparator) + 1
+	s := make([]prompt.Suggest, 0, len(keys))
+	for _, k := range keys {
+		s = append(s, prompt.Suggest{Text: k.Text[cut:]})
+	}
+	return s
+}
+
+func (c *Completer) loadRowKeySuggestions(table, prefix string) ([]prompt.Suggest, error) {
+	client, table, err := c.tableClient(table)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), keySampleTimeout)
+	defer cancel()
+
+	b, err := client.GetRows(ctx, table, bigtable.PrefixRange(prefix),
+		bigtable.LimitRows(keySampleRows),
+		bigtable.RowFilter(bigtable.ChainFilters(
+			bigtable.StripValueFilter(),
+			bigtable.CellsPerRowLimitFilter(1),
+		)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := make([]prompt.Suggest, 0, len(b.Rows))
+	for _, r := range b.Rows {
+		s = append(s, prompt.Suggest{Text: r.Key})
+	}
+	return s, nil
+}
+
+func getDecodeTypeSuggestions() []prompt.Suggest {
+	types := printer.DecodeTypes()
+	s := make([]prompt.Suggest, 0, len(types))
+	for _, t := range types {
+		s = append(s, prompt.Suggest{Text: t})
+	}
+	return s
+}
+
+func getVariableSuggestions() []prompt.Suggest {
+	return getNamedSuggestions("$", currentSession.vars)
+}
+
+func getSettingSuggestions() []prompt.Suggest {
+	settings := map[string]string{}
+	for n, v := range currentSession.settings() {
+		settings[n] = onOff(*v)
+	}
+	return getNamedSuggestions("", settings)
+}
+
+func getAliasSuggestions() []prompt.Suggest {
+	return getNamedSuggestions("", library.Aliases)
+}
+
+func getQuerySuggestions() []prompt.Suggest {
+	return getNamedSuggestions("", library.Queries)
+}
+
+// getNamedSuggestions returns the names with the prefix, and describes them with the values.
+func getNamedSuggestions(prefix string, m map[string]string) []prompt.Suggest {
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	s := make([]prompt.Suggest, 0, len(names))
+	for _, n := range names {
+		s = append(s, prompt.Suggest{Text: prefix + n, Description: m[n]})
+	}
 	return s
 }
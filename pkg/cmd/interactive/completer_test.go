@@ -2,9 +2,14 @@ package interactive
 
 import (
 	"testing"
+	"time"
 
+	"cloud.google.com/go/bigtable"
 	prompt "github.com/c-bata/go-prompt"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
 )
 
 func TestFilterDuplicateCommands(t *testing.T) {
@@ -26,9 +31,307 @@ func TestFilterDuplicateCommands(t *testing.T) {
 				{Text: "c"},
 			},
 		},
+		{
+			[]string{
+				"decode-columns=a:int", "dec",
+			},
+			[]prompt.Suggest{
+				{Text: "decode"},
+				{Text: "decode-columns"},
+			},
+			[]prompt.Suggest{
+				{Text: "decode"},
+			},
+		},
 	}
 	for _, c := range cases {
 		actual := filterDuplicateCommands(c.args, c.subcommands)
 		assert.Equal(t, c.expect, actual)
 	}
 }
+
+func TestCompleteValue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cases := []struct {
+		arg     string
+		expect  []prompt.Suggest
+		ok      bool
+		prepare func(*bt.MockClient)
+	}{
+		{
+			"start",
+			nil,
+			false,
+			func(mock *bt.MockClient) {},
+		},
+		{
+			"family=",
+			[]prompt.Suggest{
+				{Text: "d"},
+				{Text: "e"},
+			},
+			true,
+			func(mock *bt.MockClient) {
+				mock.EXPECT().Families(gomock.Any(), "users").Return([]string{"d", "e"}, nil)
+			},
+		},
+		{
+			"decode=i",
+			[]prompt.Suggest{
+				{Text: "int"},
+			},
+			true,
+			func(mock *bt.MockClient) {},
+		},
+		{
+			"decode-columns=row:int,a",
+			[]prompt.Suggest{
+				{Text: "age", Description: "d"},
+			},
+			true,
+			func(mock *bt.MockClient) {
+				mock.EXPECT().GetRows(gomock.Any(), "users", gomock.Any(), gomock.Any(), gomock.Any()).Return(
+					&bt.Bigtable{
+						Rows: []*bt.Row{
+							{
+								Key: "1",
+								Columns: []*bt.Column{
+									{Family: "d", Qualifier: "d:row"},
+									{Family: "d", Qualifier: "d:age"},
+								},
+							},
+						},
+					}, nil)
+			},
+		},
+		{
+			"decode-columns=row:f",
+			[]prompt.Suggest{
+				{Text: "float"},
+			},
+			true,
+			func(mock *bt.MockClient) {},
+		},
+		{
+			"version=1",
+			[]prompt.Suggest{},
+			true,
+			func(mock *bt.MockClient) {},
+		},
+	}
+	for _, c := range cases {
+		mockClient := bt.NewMockClient(ctrl)
+		c.prepare(mockClient)
+		completer := &Completer{client: mockClient}
+
+		actual, ok := completer.completeValue("users", cbt.ReadOptions, c.arg)
+		assert.Equal(t, c.ok, ok)
+		assert.Equal(t, c.expect, actual)
+	}
+}
+
+func TestGetRowKeySuggestions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cases := []struct {
+		prefix string
+		keys   []string
+		expect []prompt.Suggest
+	}{
+		{
+			"2",
+			[]string{"2##1", "2##2"},
+			[]prompt.Suggest{
+				{Text: "2##1"},
+				{Text: "2##2"},
+			},
+		},
+		{
+			// trimmed to after the separator
+			"a:b",
+			[]string{"a:b1"},
+			[]prompt.Suggest{
+				{Text: "b1"},
+			},
+		},
+	}
+	for _, c := range cases {
+		mockClient := bt.NewMockClient(ctrl)
+		rows := make([]*bt.Row, 0, len(c.keys))
+		for _, k := range c.keys {
+			rows = append(rows, &bt.Row{Key: k})
+		}
+		mockClient.EXPECT().GetRows(gomock.Any(), "articles", bigtable.PrefixRange(c.prefix), gomock.Any()).
+			Return(&bt.Bigtable{Rows: rows}, nil)
+		completer := &Completer{client: mockClient}
+
+		actual := completer.getRowKeySuggestions("articles", c.prefix)
+		assert.Equal(t, c.expect, actual)
+	}
+}
+
+func TestGetRowKeySuggestionsCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	mockClient.EXPECT().GetRows(gomock.Any(), "articles", bigtable.PrefixRange("a:"), gomock.Any()).
+		Return(&bt.Bigtable{Rows: []*bt.Row{{Key: "a:b1"}}}, nil).Times(1)
+	mockClient.EXPECT().GetRows(gomock.Any(), "articles", bigtable.PrefixRange("a:b"), gomock.Any()).
+		Return(&bt.Bigtable{Rows: []*bt.Row{{Key: "a:b1"}}}, nil).Times(1)
+	completer := &Completer{client: mockClient, cache: newMetadataCache(time.Minute)}
+
+	// read once for each prefix while typing
+	for _, prefix := range []string{"a:", "a:b", "a:", "a:b"} {
+		assert.Equal(t, []prompt.Suggest{{Text: "b1"}}, completer.getRowKeySuggestions("articles", prefix), prefix)
+	}
+}
+
+func TestCompleteWithArguments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cases := []struct {
+		args    []string
+		expect  []string
+		prepare func(*bt.MockClient)
+	}{
+		{
+			[]string{"he"},
+			[]string{"help"},
+			func(mock *bt.MockClient) {},
+		},
+		{
+			[]string{"help", "lo"},
+			[]string{"lookup"},
+			func(mock *bt.MockClient) {},
+		},
+		{
+			[]string{"lookup", "u"},
+			[]string{"users"},
+			func(mock *bt.MockClient) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"articles", "users"}, nil)
+			},
+		},
+		{
+			[]string{"lookup", "users", "1", "v"},
+			[]string{"version"},
+			func(mock *bt.MockClient) {},
+		},
+		{
+			[]string{"lookup", "users", "1", "decode=int", "dec"},
+			[]string{"decode-columns"},
+			func(mock *bt.MockClient) {},
+		},
+		{
+			[]string{"watch", "5s", "lo"},
+			[]string{"lookup"},
+			func(mock *bt.MockClient) {},
+		},
+		{
+			[]string{"watch", "5s", "lookup", "users", "1", "v"},
+			[]string{"version"},
+			func(mock *bt.MockClient) {},
+		},
+		{
+			[]string{"ls", ""},
+			[]string{},
+			func(mock *bt.MockClient) {},
+		},
+		{
+			[]string{"unknown", ""},
+			[]string{},
+			func(mock *bt.MockClient) {},
+		},
+	}
+	for _, c := range cases {
+		mockClient := bt.NewMockClient(ctrl)
+		c.prepare(mockClient)
+		completer := &Completer{client: mockClient}
+
+		actual := []string{}
+		for _, s := range completer.completeWithArguments(c.args...) {
+			actual = append(actual, s.Text)
+		}
+		assert.Equal(t, c.expect, actual)
+	}
+}
+
+func TestCompleteWithSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func() { currentSession = newSession() }()
+
+	currentSession.table = "users"
+	currentSession.vars = map[string]string{"t": "users", "p": "2##"}
+
+	cases := []struct {
+		args    []string
+		expect  []string
+		prepare func(*bt.MockClient)
+	}{
+		{
+			[]string{"read", "prefix=$"},
+			[]string{"$p", "$t"},
+			func(mock *bt.MockClient) {},
+		},
+		{
+			// the default table is used
+			[]string{"read", "fam"},
+			[]string{"family"},
+			func(mock *bt.MockClient) {},
+		},
+		{
+			// the variable is expanded
+			[]string{"read", "$t", "family="},
+			[]string{"d"},
+			func(mock *bt.MockClient) {
+				mock.EXPECT().Families(gomock.Any(), "users").Return([]string{"d"}, nil)
+			},
+		},
+	}
+	for _, c := range cases {
+		mockClient := bt.NewMockClient(ctrl)
+		c.prepare(mockClient)
+		completer := &Completer{client: mockClient}
+
+		actual := []string{}
+		for _, s := range completer.completeWithArguments(c.args...) {
+			actual = append(actual, s.Text)
+		}
+		assert.Equal(t, c.expect, actual)
+	}
+}
+
+func TestCompleteProfileTable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func(f func(string) (bt.Client, error)) { cbt.ConnectProfile = f }(cbt.ConnectProfile)
+
+	mockClient := bt.NewMockClient(ctrl)
+	profileClient := bt.NewMockClient(ctrl)
+	profileClient.EXPECT().Tables(gomock.Any()).Return([]string{"articles", "users"}, nil)
+	cbt.ConnectProfile = func(name string) (bt.Client, error) {
+		assert.Equal(t, "prod", name)
+		return profileClient, nil
+	}
+	completer := &Completer{client: mockClient}
+
+	actual := []string{}
+	for _, s := range completer.completeWithArguments("diff", "prod:u") {
+		actual = append(actual, s.Text)
+	}
+	assert.Equal(t, []string{"users"}, actual)
+
+	// the row keys are read from the table of the profile
+	profileClient.EXPECT().GetRows(gomock.Any(), "users", bigtable.PrefixRange("1"), gomock.Any()).
+		Return(&bt.Bigtable{Rows: []*bt.Row{{Key: "10"}}}, nil)
+	actual = []string{}
+	for _, s := range completer.completeWithArguments("diff", "prod:users", "users", "start=1") {
+		actual = append(actual, s.Text)
+	}
+	assert.Equal(t, []string{"10"}, actual)
+}
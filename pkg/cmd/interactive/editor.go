@@ -0,0 +1,62 @@
+package interactive
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+)
+
+// editCommand opens the editor with the current statement, like psql
+const editCommand = `\e`
+
+// editStatement opens $EDITOR with the statement, and returns the edited statement.
+func editStatement(statement string) (string, error) {
+	f, err := ioutil.TempFile("", "btcli")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if statement != "" {
+		statement += "\n"
+	}
+	if _, err := f.WriteString(statement); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := shellCommand(fmt.Sprintf("%s %s", editor, f.Name()))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", cbt.UsageError("Failed to edit: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return joinLines(strings.Split(string(data), "\n")), nil
+}
+
+// joinLines joins the continued lines to a statement, separated by a space.
+func joinLines(lines []string) string {
+	words := make([]string, 0, len(lines))
+	for _, l := range lines {
+		l = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(l), "\\"))
+		if l != "" {
+			words = append(words, l)
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,90 @@
+package interactive
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+func TestJoinLines(t *testing.T) {
+	cases := []struct {
+		lines  []string
+		expect string
+	}{
+		{[]string{"ls"}, "ls"},
+		{[]string{"read users \\", "  prefix=1\\", "count=2"}, "read users prefix=1 count=2"},
+		{[]string{"read users", "", "  count=2  ", ""}, "read users count=2"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expect, joinLines(c.lines))
+	}
+}
+
+func TestExecutorMultiLine(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().Count(gomock.Any(), "users", gomock.Any(), gomock.Any()).Return(3, nil)
+
+	h := &history{}
+	exited := 0
+	e := &Executor{client: mockClient, history: h, exit: func() { exited++ }}
+	e.Do("count \\")
+	e.Do("users \\")
+	p, _ := e.prefix()
+	assert.Equal(t, "... ", p)
+	assert.Equal(t, 0, exited)
+	e.Do("prefix=1")
+
+	assert.Equal(t, "3\n", buf.String())
+	assert.Equal(t, []string{"count users prefix=1"}, h.entries)
+	p, _ = e.prefix()
+	assert.NotEqual(t, "... ", p)
+	// the prompt is reloaded not to keep the continued lines in its history
+	assert.Equal(t, 1, exited)
+	assert.True(t, e.reload)
+
+	// exits without the reload
+	e.reload = false
+	e.Do("exit \\")
+	e.Do("3")
+	assert.Equal(t, 2, exited)
+	assert.False(t, e.reload)
+	assert.Equal(t, 3, e.exitCode)
+}
+
+func TestExecutorEdit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer os.Setenv("EDITOR", os.Getenv("EDITOR"))
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().Count(gomock.Any(), "users", gomock.Any(), gomock.Any()).Return(3, nil).Times(2)
+
+	os.Setenv("EDITOR", "sed -i -e s/articles/users/")
+	h := &history{}
+	e := &Executor{client: mockClient, history: h}
+
+	// edit the continued lines
+	e.Do("count \\")
+	e.Do("articles \\")
+	e.Do(`\e`)
+	assert.Equal(t, []string{"count users"}, h.entries)
+
+	// edit the last statement
+	h.entries = append(h.entries, "count articles prefix=1")
+	e.Do(`\e`)
+	assert.Equal(t, "3\n3\n", buf.String())
+	assert.Equal(t, []string{"count users", "count articles prefix=1", "count users prefix=1"}, h.entries)
+}
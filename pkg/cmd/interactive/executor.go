@@ -5,18 +5,20 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
 )
 
 // Avoid to circular dependencies
 var (
-	doHelpFn func(context.Context, bigtable.Client, ...string)
+	doHelpFn func(context.Context, bigtable.Client, ...string) error
 )
 
-func doHelp(ctx context.Context, client bigtable.Client, args ...string) {
-	doHelpFn(ctx, client, args...)
+func doHelp(ctx context.Context, client bigtable.Client, args ...string) error {
+	return doHelpFn(ctx, client, args...)
 }
 
 func init() {
@@ -25,50 +27,290 @@ func init() {
 
 // Executor provides exec command handler
 type Executor struct {
-	client  bigtable.Client
-	history io.Writer
+	client bigtable.Client
+	// clients is the clients of the main and the profile instances to sum the statistics, only the main client is counted when nil
+	clients *bigtable.Clients
+	history *history
+	// paging represents the output is paged when the session enables the pager
+	paging bool
+
+	// exit stops the prompt loop, called by the exit command
+	exit func()
+	// exitCode is the code given by the exit command
+	exitCode int
+	exiting  bool
+
+	// pending is the lines continued by the trailing backslash
+	pending []string
+	// reload represents the prompt loop is stopped to start again with the history.
+	// The prompt keeps the continued lines and "\e" in its own history, so it is reloaded after such statements.
+	reload bool
 }
 
-// Do provides execute command
+// Do provides execute command.
+// The line ends with a backslash is continued to the next line, and "\e" edits the statement with the editor.
 func (e *Executor) Do(s string) {
+	if strings.HasSuffix(strings.TrimSpace(s), "\\") {
+		e.pending = append(e.pending, s)
+		return
+	}
+	lines := append(e.pending, s)
+	e.pending = nil
+	edited := strings.TrimSpace(s) == editCommand
+	if len(lines) > 1 || edited {
+		defer e.reloadPrompt()
+	}
+
+	if edited {
+		lines = lines[:len(lines)-1]
+		if len(lines) == 0 && e.history != nil && len(e.history.entries) > 0 {
+			// edit the last statement like psql
+			lines = []string{e.history.entries[len(e.history.entries)-1]}
+		}
+		edited, err := editStatement(strings.Join(lines, "\n"))
+		if err != nil {
+			printError(e.client.ErrStream(), err)
+			return
+		}
+		lines = []string{edited}
+	}
+	e.run(joinLines(lines))
+}
+
+// reloadPrompt stops the prompt loop to reload the history, unless the prompt is exiting or the history is not available.
+func (e *Executor) reloadPrompt() {
+	if e.exiting || e.exit == nil || e.history == nil {
+		return
+	}
+	e.reload = true
+	e.exit()
+}
+
+// prefix returns the prompt string, or the continuation prompt while the lines are continued.
+func (e *Executor) prefix() (string, bool) {
+	if len(e.pending) > 0 {
+		return "... ", true
+	}
+	return currentSession.prefix()
+}
+
+// run runs the statement and prints the error, and the statistics when the timing is on.
+func (e *Executor) run(s string) {
+	var sw *stopwatch
+	if currentSession.timing {
+		sw = startStopwatch(e.stats)
+	}
+	err := e.execute(context.Background(), s)
+	if ee, ok := err.(*exitError); ok {
+		e.exitCode = ee.code
+		e.exiting = true
+		if e.exit != nil {
+			e.exit()
+		}
+		return
+	}
+	if err != nil {
+		printError(e.client.ErrStream(), err)
+	}
+	// not for the statement turning on or off the timing
+	if sw != nil && currentSession.timing {
+		sw.print(e.client.ErrStream())
+	}
+}
+
+// stats returns the statistics of the requests, including the clients of the profiles used by "<profile>:<table>".
+func (e *Executor) stats() bigtable.Stats {
+	if e.clients == nil {
+		return e.client.Stats()
+	}
+	return e.clients.Stats()
+}
+
+// statement is the parsed command line
+type statement struct {
+	cmd Command
+	// args is the arguments of the command, filled with the default table
+	args     []string
+	redirect *redirect
+
+	// raw is the arguments before the variables are expanded, to save or to watch the statement as typed
+	raw []string
+}
+
+// statementKey is the context key of the running statement
+type statementKey struct{}
+
+// rawArgs returns the arguments before the expansion of the running statement, or the given args when not found.
+func rawArgs(ctx context.Context, args []string) []string {
+	if st, ok := ctx.Value(statementKey{}).(*statement); ok {
+		return st.raw
+	}
+	return args
+}
+
+// prompts reports whether the statement waits for the answer on the terminal, the pager can not share the terminal.
+func (st *statement) prompts() bool {
+	return st.cmd.Prompts != nil && st.cmd.Prompts(st.args)
+}
+
+// parse resolves the aliases and the variables of the line, and finds the command.
+func parse(line string) (*statement, error) {
+	line = resolveAlias(resolveTiming(line))
+	expanded, err := currentSession.expand(line)
+	if err != nil {
+		return nil, err
+	}
+	return newStatement(strings.Split(expanded, " "), strings.Split(line, " "))
+}
+
+// parseArgs is parse for the arguments already split by the shell, the argument may have the spaces.
+func parseArgs(words []string) (*statement, error) {
+	raw := words
+	if cmd, ok := library.Aliases[words[0]]; ok {
+		raw = append(strings.Split(cmd, " "), words[1:]...)
+	}
+	args := make([]string, 0, len(raw))
+	for _, a := range raw {
+		expanded, err := currentSession.expand(a)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, expanded)
+	}
+	return newStatement(args, raw)
+}
+
+// newStatement finds the command of the expanded arguments, raw is the arguments before the expansion.
+func newStatement(args, raw []string) (*statement, error) {
+	args, rd, err := splitRedirect(args)
+	if err != nil {
+		return nil, err
+	}
+	raw, _, err = splitRedirect(raw)
+	if err != nil {
+		// the redirect is made by the variable
+		raw = args
+	}
+	c, ok := findCommand(args[0])
+	if !ok {
+		return nil, cbt.UsageError("Unknown command: %s", args[0])
+	}
+	return &statement{
+		cmd:      c,
+		args:     currentSession.withTable(c, args[1:]),
+		redirect: rd,
+		raw:      raw[1:],
+	}, nil
+}
+
+// execute runs the command line and returns the error of the command.
+func (e *Executor) execute(ctx context.Context, s string) error {
 	s = strings.TrimSpace(s)
 	if s == "" {
-		return
+		return nil
 	}
 
-	ctx := context.Background()
-	args := strings.Split(s, " ")
-	cmd := args[0]
+	st, err := parse(s)
+	if err != nil {
+		return err
+	}
+	// record the line before the expansion to reuse it with the other variables
+	e.history.add(s)
+	return e.executeStatement(ctx, st)
+}
 
-	for _, c := range commands {
-		if cmd == c.Name {
-			if e.history != nil {
-				fmt.Fprintln(e.history, strings.Join(args, " "))
-			}
+// executeStatement runs the parsed statement with the redirect or the pager.
+func (e *Executor) executeStatement(ctx context.Context, st *statement) error {
+	// the long scans without the timeout are stopped by Ctrl-C
+	ctx, cancel := cbt.WithInterrupt(ctx)
+	defer cancel()
 
-			c.Runner(ctx, e.client, args[1:]...)
-			return
+	client := e.client
+	if rd := st.redirect; rd != nil {
+		w, err := rd.open(client.OutStream(), client.ErrStream())
+		if err != nil {
+			return cbt.UsageError("Failed to redirect: %v", err)
+		}
+		defer w.Close()
+		client = bigtable.RedirectOutStream(client, w)
+	} else if e.paging && currentSession.pager && !st.cmd.Continuous && !st.prompts() {
+		if h := terminalHeight(); h > 0 {
+			p := newPager(client.OutStream(), client.ErrStream(), h, os.Getenv("PAGER"), cancel)
+			defer p.Close()
+			client = bigtable.RedirectOutStream(client, p)
 		}
 	}
-	fmt.Fprintf(e.client.ErrStream(), "Unknown command: %s\n", cmd)
+
+	c := st.cmd
+	err := c.Runner(context.WithValue(ctx, statementKey{}, st), client, st.args...)
+	if c.Admin {
+		metadata.clear()
+	}
+	if ctx.Err() == context.Canceled {
+		// stopped by the user
+		return nil
+	}
+	return err
+}
+
+// printError prints the error with the category.
+func printError(w io.Writer, err error) {
+	fmt.Fprintf(w, "%s error: %v\n", cbt.KindOf(err), err)
+}
+
+// exitCode returns the exit code corresponding to the category of the error.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitCodeOK
+	}
+	if ee, ok := err.(*exitError); ok {
+		return ee.code
+	}
+	switch cbt.KindOf(err) {
+	case cbt.KindUsage:
+		return ExitCodeInvalidArgsError
+	case cbt.KindNotFound:
+		return ExitCodeNotFoundError
+	case cbt.KindPermission:
+		return ExitCodePermissionError
+	case cbt.KindDeadline:
+		return ExitCodeDeadlineError
+	default:
+		return ExitCodeServerError
+	}
 }
 
-func doExit(ctx context.Context, client bigtable.Client, args ...string) {
+// exitError is returned by the exit command to stop the prompt loop
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit %d", e.code)
+}
+
+func doExit(ctx context.Context, client bigtable.Client, args ...string) error {
+	code := ExitCodeOK
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			return cbt.UsageError("Invalid args: exit [<code>]")
+		}
+		code = n
+	}
 	fmt.Fprintln(client.OutStream(), "Bye!")
-	os.Exit(0)
+	return &exitError{code: code}
 }
 
-func lazyDoHelp(ctx context.Context, client bigtable.Client, args ...string) {
-	if len(args) == 1 {
+func lazyDoHelp(ctx context.Context, client bigtable.Client, args ...string) error {
+	if len(args) == 0 {
 		usage(client.OutStream())
-		return
+		return nil
 	}
-	cmd := args[1]
-	for _, c := range commands {
-		if c.Name == cmd {
-			fmt.Fprintln(client.OutStream(), c.Usage)
-			return
-		}
+	cmd := args[0]
+	if c, ok := findCommand(cmd); ok {
+		fmt.Fprintln(client.OutStream(), c.FullUsage())
+		return nil
 	}
-	fmt.Fprintf(client.ErrStream(), "Unknown command: %s\n", cmd)
+	return cbt.UsageError("Unknown command: %s", cmd)
 }
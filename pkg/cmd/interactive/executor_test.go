@@ -1 +1,246 @@
 package interactive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	prompt "github.com/c-bata/go-prompt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/config"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDoHelp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cases := []struct {
+		args   []string
+		expect string
+	}{
+		{
+			[]string{"lookup"},
+			`lookup <table> <row> [family=<column_family>] [version=<n>]
+	family         Read only columns family with <columns_family>
+	version        Read only latest <n> columns
+	decode         Decode big-endian value. <string|int|float>
+	decode-columns Decode big-endian value with columns. <column_name:<string|int|float>[,<column_name:...>]
+`,
+		},
+		{
+			[]string{"ls"},
+			"ls\n",
+		},
+		{
+			[]string{"unknown"},
+			"usage error: Unknown command: unknown\n",
+		},
+	}
+	for _, c := range cases {
+		mockClient := bt.NewMockClient(ctrl)
+		var buf bytes.Buffer
+		mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+		mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+
+		if err := doHelp(context.Background(), mockClient, c.args...); err != nil {
+			printError(&buf, err)
+		}
+		assert.Equal(t, c.expect, buf.String())
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		err    error
+		expect int
+	}{
+		{nil, ExitCodeOK},
+		{cbt.UsageError("Invalid args"), ExitCodeInvalidArgsError},
+		{cbt.NotFoundError("Row not found"), ExitCodeNotFoundError},
+		{status.Error(codes.PermissionDenied, "denied"), ExitCodePermissionError},
+		{context.DeadlineExceeded, ExitCodeDeadlineError},
+		{errors.New("unavailable"), ExitCodeServerError},
+		{&exitError{code: 3}, 3},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expect, exitCode(c.err), "%v", c.err)
+	}
+}
+
+func TestDoExit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+
+	exited := 0
+	e := &Executor{client: mockClient, exit: func() { exited++ }}
+
+	e.Do("exit abc")
+	assert.Equal(t, "usage error: Invalid args: exit [<code>]\n", buf.String())
+	assert.Equal(t, 0, exited)
+
+	buf.Reset()
+	e.Do("quit 3")
+	assert.Equal(t, "Bye!\n", buf.String())
+	assert.Equal(t, 1, exited)
+	assert.Equal(t, 3, e.exitCode)
+}
+
+func TestExecuteAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer metadata.clear()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ReadRows(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	metadata.get("columns/t2", func() ([]prompt.Suggest, error) {
+		return []prompt.Suggest{{Text: "d:row"}}, nil
+	})
+
+	e := &Executor{client: mockClient}
+	assert.NoError(t, e.execute(context.Background(), "sync t1 t2"))
+	assert.Contains(t, buf.String(), "t2 is already in sync\n")
+	assert.Empty(t, metadata.entries)
+}
+
+func TestParseArgs(t *testing.T) {
+	defer func() { currentSession = newSession() }()
+	defer func() { library = config.NewLibrary("") }()
+	currentSession.vars["p"] = "a b"
+	library.Aliases["rr"] = "read users decode=int"
+
+	cases := []struct {
+		words  []string
+		cmd    string
+		args   []string
+		raw    []string
+		expect string
+	}{
+		{
+			// the spaces quoted by the shell are kept
+			words: []string{"read", "users", "regex=a b"},
+			cmd:   "read",
+			args:  []string{"users", "regex=a b"},
+			raw:   []string{"users", "regex=a b"},
+		},
+		{
+			words: []string{"rr", "prefix=$p"},
+			cmd:   "read",
+			args:  []string{"users", "decode=int", "prefix=a b"},
+			raw:   []string{"users", "decode=int", "prefix=$p"},
+		},
+		{
+			words:  []string{"read", "users", "prefix=$q"},
+			expect: "Undefined variable: q",
+		},
+	}
+	for _, c := range cases {
+		st, err := parseArgs(c.words)
+		if c.expect != "" {
+			assert.EqualError(t, err, c.expect)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, c.cmd, st.cmd.Name)
+		assert.Equal(t, c.args, st.args)
+		assert.Equal(t, c.raw, st.raw)
+	}
+}
+
+func TestExecuteInterrupt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().Count(gomock.Any(), "t1", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ string, _ bigtable.RowRange, _ ...bigtable.ReadOption) (int, error) {
+			// Ctrl-C while scanning
+			p, err := os.FindProcess(os.Getpid())
+			if err != nil {
+				return 0, err
+			}
+			if err := p.Signal(os.Interrupt); err != nil {
+				return 0, err
+			}
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+
+	e := &Executor{client: mockClient}
+	assert.NoError(t, e.execute(context.Background(), "count t1"))
+	assert.Empty(t, buf.String())
+}
+
+func TestExecutePrompts(t *testing.T) {
+	defer func(f func() int) { terminalHeight = f }(terminalHeight)
+	terminalHeight = func() int { return 24 }
+	defer func(f func(io.Writer) bool) { cbt.IsTerminal = f }(cbt.IsTerminal)
+
+	cases := []struct {
+		line   string
+		expect func(*bt.MockClient)
+	}{
+		{
+			line: "read t1 page-size=1",
+			expect: func(mockClient *bt.MockClient) {
+				mockClient.EXPECT().GetRows(gomock.Any(), "t1", gomock.Any(), gomock.Any()).Return(&bt.Bigtable{
+					Table: "t1",
+					Rows:  []*bt.Row{{Key: "1"}},
+				}, nil)
+				mockClient.EXPECT().GetRows(gomock.Any(), "t1", gomock.Any(), gomock.Any()).Return(&bt.Bigtable{Table: "t1"}, nil)
+			},
+		},
+		{
+			line: "sync t1 t2",
+			expect: func(mockClient *bt.MockClient) {
+				mockClient.EXPECT().ReadRows(gomock.Any(), "t1", gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, _ bigtable.RowRange, fn func(*bt.Row) bool, _ ...bigtable.ReadOption) error {
+						fn(&bt.Row{Key: "1", Columns: []*bt.Column{{Family: "d", Qualifier: "d:row", Value: []byte("madoka")}}})
+						return nil
+					})
+				mockClient.EXPECT().ReadRows(gomock.Any(), "t2", gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+
+		mockClient := bt.NewMockClient(ctrl)
+		var buf bytes.Buffer
+		mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+		mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+		c.expect(mockClient)
+
+		// the prompt is shown only on the terminal, not behind the pager
+		var out io.Writer
+		cbt.IsTerminal = func(w io.Writer) bool {
+			out = w
+			return false
+		}
+		e := &Executor{client: mockClient, paging: true}
+		e.execute(context.Background(), c.line)
+		assert.Equal(t, io.Writer(&buf), out, c.line)
+
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,158 @@
+package interactive
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	prompt "github.com/c-bata/go-prompt"
+)
+
+// history is the command history stored in the file.
+// Consecutive duplicates are collapsed, and the entries are capped at the size.
+type history struct {
+	size    int
+	entries []string
+	path    string
+	file    *os.File
+}
+
+// historyPath returns the path of the history file, separated by the project and the instance when perInstance is true.
+func historyPath(project, instance string, perInstance bool) (string, error) {
+	u, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	name := ".btcli_history"
+	if perInstance {
+		name = fmt.Sprintf("%s_%s_%s", name, project, instance)
+	}
+	return filepath.Join(u.HomeDir, name), nil
+}
+
+// openHistory reads the history file and opens it to append the entries.
+func openHistory(path string, size int) (*history, error) {
+	h, err := readHistory(path, size)
+	if err != nil {
+		return nil, err
+	}
+	h.file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// readHistory reads the history file.
+// The file is rewritten when the entries are collapsed or capped.
+func readHistory(path string, size int) (*history, error) {
+	h := &history{size: size, path: path}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	lines := 0
+	s := bufio.NewScanner(strings.NewReader(string(data)))
+	for s.Scan() {
+		lines++
+		h.push(s.Text())
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if lines != len(h.entries) {
+		if err := h.rewrite(path); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// push adds the entry to the memory, returns false when it is empty or a duplicate of the last entry.
+func (h *history) push(line string) bool {
+	if line == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == line) {
+		return false
+	}
+	h.entries = append(h.entries, line)
+	if h.size > 0 && len(h.entries) > h.size {
+		h.entries = h.entries[len(h.entries)-h.size:]
+	}
+	return true
+}
+
+// rewrite replaces the file with the current entries.
+func (h *history) rewrite(path string) error {
+	tmp := path + ".tmp"
+	data := strings.Join(h.entries, "\n")
+	if len(h.entries) > 0 {
+		data += "\n"
+	}
+	if err := ioutil.WriteFile(tmp, []byte(data), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// add adds the entry and appends it to the file.
+func (h *history) add(line string) {
+	if h == nil || !h.push(line) || h.file == nil {
+		return
+	}
+	fmt.Fprintln(h.file, line)
+}
+
+// Close closes the history file, and caps the file grown by the entries appended in the session.
+// The file is read again to keep the entries appended by the other sessions.
+func (h *history) Close() error {
+	if h == nil || h.file == nil {
+		return nil
+	}
+	if err := h.file.Close(); err != nil {
+		return err
+	}
+	_, err := readHistory(h.path, h.size)
+	return err
+}
+
+// reverseSearch searches the history backward from the newest entry by the text of the buffer.
+// Repeating the search continues to the older entries while the buffer is not edited.
+type reverseSearch struct {
+	history *history
+	query   string
+	match   string
+	index   int
+}
+
+// next replaces the buffer with the next older entry that contains the query.
+func (r *reverseSearch) next(buf *prompt.Buffer) {
+	if r.history == nil {
+		return
+	}
+	if text := buf.Text(); r.match == "" || text != r.match {
+		// new search by the edited text
+		r.query = text
+		r.index = len(r.history.entries)
+	}
+
+	for i := r.index - 1; i >= 0; i-- {
+		e := r.history.entries[i]
+		if e == r.match || !strings.Contains(e, r.query) {
+			continue
+		}
+		r.index = i
+		r.match = e
+		replaceText(buf, e)
+		return
+	}
+}
+
+func replaceText(buf *prompt.Buffer, text string) {
+	buf.CursorRight(len([]rune(buf.Document().TextAfterCursor())))
+	buf.DeleteBeforeCursor(len([]rune(buf.Text())))
+	buf.InsertText(text, false, true)
+}
@@ -0,0 +1,74 @@
+package interactive
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	prompt "github.com/c-bata/go-prompt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".btcli_history")
+
+	// collapsed and capped at loading
+	data := "ls\nread a\nread a\n\nread b\nls\n"
+	assert.NoError(t, ioutil.WriteFile(path, []byte(data), 0600))
+	h, err := openHistory(path, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"read a", "read b", "ls"}, h.entries)
+
+	h.add("ls")
+	h.add("count a")
+	h.add("count a")
+	assert.Equal(t, []string{"read b", "ls", "count a"}, h.entries)
+	assert.NoError(t, h.Close())
+
+	actual, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	// capped at closing
+	assert.Equal(t, "read b\nls\ncount a\n", string(actual))
+
+	h, err = openHistory(path, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"read b", "ls", "count a"}, h.entries)
+	assert.NoError(t, h.Close())
+}
+
+func TestHistoryPath(t *testing.T) {
+	p, err := historyPath("project", "instance", false)
+	assert.NoError(t, err)
+	assert.Equal(t, ".btcli_history", filepath.Base(p))
+
+	p, err = historyPath("project", "instance", true)
+	assert.NoError(t, err)
+	assert.Equal(t, ".btcli_history_project_instance", filepath.Base(p))
+}
+
+func TestReverseSearch(t *testing.T) {
+	h := &history{entries: []string{"read users", "ls", "read articles", "count users", "read articles"}}
+	r := &reverseSearch{history: h}
+
+	buf := prompt.NewBuffer()
+	buf.InsertText("read", false, true)
+	r.next(buf)
+	assert.Equal(t, "read articles", buf.Text())
+	// continue to the older entry, skips the same entry
+	r.next(buf)
+	assert.Equal(t, "read users", buf.Text())
+	// no more entries
+	r.next(buf)
+	assert.Equal(t, "read users", buf.Text())
+
+	// new search by the edited text
+	replaceText(buf, "users")
+	r.next(buf)
+	assert.Equal(t, "count users", buf.Text())
+}
@@ -1,17 +1,17 @@
 package interactive
 
 import (
-	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
-	"os/user"
+	"sync/atomic"
 
 	prompt "github.com/c-bata/go-prompt"
-	"github.com/pkg/errors"
 	"github.com/takashabe/btcli/pkg/bigtable"
 	"github.com/takashabe/btcli/pkg/config"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
 )
 
 // exit codes
@@ -22,6 +22,10 @@ const (
 	ExitCodeError = 10 + iota
 	ExitCodeParseError
 	ExitCodeInvalidArgsError
+	ExitCodeNotFoundError
+	ExitCodePermissionError
+	ExitCodeDeadlineError
+	ExitCodeServerError
 )
 
 // CLI is the command line interface object
@@ -34,38 +38,77 @@ type CLI struct {
 }
 
 // Run invokes the CLI with the given arguments
+// When the command is given after the flags, runs it once and exits with the code of the result.
 func (c *CLI) Run(args []string) int {
-	fmt.Fprintf(c.OutStream, "btcli Version: %s(%s)\n", c.Version, c.Sum)
-	fmt.Fprintf(c.OutStream, "Please use `exit` or `Ctrl-D` to exit this program.\n")
-
 	conf, err := c.loadConfig(args)
 	if err != nil {
 		fmt.Fprintf(c.ErrStream, "args parse error: %v\n", err)
 		return ExitCodeParseError
 	}
 
-	histories := []string{}
-	f, err := loadHistoryFile(conf)
+	library = conf.Library
+	currentSession.project = conf.Project
+
+	clients := bigtable.NewClients(
+		bigtable.WithOutStream(c.OutStream),
+		bigtable.WithErrStream(c.ErrStream),
+	)
+	defer clients.Close()
+	client, err := clients.Get(bigtable.Profile{Project: conf.Project, Instance: conf.Instance})
 	if err != nil {
-		// Continue processing even if an error occurred at open a file
-		fmt.Fprintf(c.ErrStream, "failed to a history file open: %v\n", err)
-	} else {
-		s := bufio.NewScanner(f)
-		for s.Scan() {
-			histories = append(histories, s.Text())
+		fmt.Fprintf(c.ErrStream, "failed to initialized bigtable repository: %v\n", err)
+		return ExitCodeError
+	}
+	cbt.ConnectProfile = connectProfile(clients)
+
+	if flag.NArg() > 0 {
+		executor := Executor{
+			client:  client,
+			clients: clients,
 		}
-		defer f.Close()
+		var sw *stopwatch
+		if conf.Stats {
+			sw = startStopwatch(executor.stats)
+		}
+		// the arguments are not joined, to keep the spaces in the argument quoted by the shell
+		st, err := parseArgs(flag.Args())
+		if err == nil {
+			err = executor.executeStatement(context.Background(), st)
+		}
+		if _, ok := err.(*exitError); !ok && err != nil {
+			printError(c.ErrStream, err)
+		}
+		if sw != nil {
+			sw.print(c.ErrStream)
+		}
+		return exitCode(err)
 	}
 
-	p, err := c.preparePrompt(conf, f, histories)
+	fmt.Fprintf(c.OutStream, "btcli Version: %s(%s)\n", c.Version, c.Sum)
+	fmt.Fprintf(c.OutStream, "Please use `exit` or `Ctrl-D` to exit this program.\n")
+
+	h, err := loadHistory(conf)
 	if err != nil {
-		fmt.Fprintf(c.ErrStream, "failed to initialized prompt: %v\n", err)
-		return ExitCodeError
+		// Continue processing even if an error occurred at open a file
+		fmt.Fprintf(c.ErrStream, "failed to a history file open: %v\n", err)
+	}
+	defer h.Close()
+
+	currentSession.instance = conf.Instance
+	executor, newPrompt := c.preparePrompt(client, clients, h)
+	for {
+		// returns by the exit command, Ctrl-D or the reload.
+		// The prompt exits the process by os.Exit on the signals, so only the exit command and Ctrl-D close the clients and the history.
+		newPrompt().Run()
+		if !executor.reload {
+			break
+		}
+		executor.reload = false
+		// overwrite the empty prompt line printed by stopping the prompt
+		fmt.Fprint(c.OutStream, cursorUp)
 	}
-	p.Run()
 
-	// TODO: This is dead code. Invoke os.Exit by the prompt.Run
-	return ExitCodeOK
+	return executor.exitCode
 }
 
 func (c *CLI) loadConfig(args []string) (*config.Config, error) {
@@ -87,34 +130,70 @@ func usage(w io.Writer) {
 	flag.CommandLine.PrintDefaults()
 }
 
-func (c *CLI) preparePrompt(conf *config.Config, writer io.Writer, histories []string) (*prompt.Prompt, error) {
-	client, err := bigtable.NewClient(conf.Project, conf.Instance)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to initialized bigtable repository:%v", err)
-	}
-
-	executor := Executor{
-		history: writer,
+// preparePrompt returns the executor, and the function to create the prompt with the current history.
+func (c *CLI) preparePrompt(client bigtable.Client, clients *bigtable.Clients, h *history) (*Executor, func() *prompt.Prompt) {
+	parser := &exitParser{ConsoleParser: prompt.NewStandardInputParser()}
+	search := &reverseSearch{history: h}
+	executor := &Executor{
+		history: h,
 		client:  client,
+		clients: clients,
+		paging:  true,
+		exit:    parser.exit,
 	}
 	completer := Completer{
 		client: client,
+		cache:  metadata,
 	}
 
-	return prompt.New(
-		executor.Do,
-		completer.Do,
-		prompt.OptionHistory(histories),
-		prompt.OptionPreviewSuggestionTextColor(prompt.Blue),
-		prompt.OptionSelectedSuggestionBGColor(prompt.LightGray),
-		prompt.OptionSuggestionBGColor(prompt.DarkGray),
-	), nil
+	return executor, func() *prompt.Prompt {
+		var histories []string
+		if h != nil {
+			// copy not to share the array with the prompt
+			histories = append(histories, h.entries...)
+		}
+		return prompt.New(
+			executor.Do,
+			completer.Do,
+			prompt.OptionParser(parser),
+			prompt.OptionHistory(histories),
+			prompt.OptionAddKeyBind(prompt.KeyBind{Key: prompt.ControlR, Fn: search.next}),
+			prompt.OptionLivePrefix(executor.prefix),
+			// complete the value of "key=value" and "column:type,..." separately
+			prompt.OptionCompletionWordSeparator(completionWordSeparator),
+			prompt.OptionPreviewSuggestionTextColor(prompt.Blue),
+			prompt.OptionSelectedSuggestionBGColor(prompt.LightGray),
+			prompt.OptionSuggestionBGColor(prompt.DarkGray),
+		)
+	}
+}
+
+// cursorUp moves the cursor to the previous line
+const cursorUp = "\x1b[1A"
+
+// exitParser is the console parser that stops the prompt loop.
+// The prompt only returns by Ctrl-D, so it sends Ctrl-D on behalf of the user after the exit command.
+type exitParser struct {
+	prompt.ConsoleParser
+	exiting int32
+}
+
+func (p *exitParser) exit() {
+	atomic.StoreInt32(&p.exiting, 1)
+}
+
+// Read returns Ctrl-D once after exit is called.
+func (p *exitParser) Read() ([]byte, error) {
+	if atomic.CompareAndSwapInt32(&p.exiting, 1, 0) {
+		return []byte{0x04}, nil
+	}
+	return p.ConsoleParser.Read()
 }
 
-func loadHistoryFile(conf *config.Config) (*os.File, error) {
-	u, err := user.Current()
+func loadHistory(conf *config.Config) (*history, error) {
+	path, err := historyPath(conf.Project, conf.Instance, conf.HistoryPerInstance)
 	if err != nil {
 		return nil, err
 	}
-	return os.OpenFile(u.HomeDir+"/.btcli_history", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	return openHistory(path, conf.HistorySize)
 }
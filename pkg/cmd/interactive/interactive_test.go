@@ -0,0 +1,31 @@
+package interactive
+
+import (
+	"testing"
+
+	prompt "github.com/c-bata/go-prompt"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubParser struct {
+	prompt.ConsoleParser
+}
+
+func (stubParser) Read() ([]byte, error) {
+	return []byte("a"), nil
+}
+
+func TestExitParser(t *testing.T) {
+	p := &exitParser{ConsoleParser: stubParser{}}
+
+	b, _ := p.Read()
+	assert.Equal(t, []byte("a"), b)
+
+	p.exit()
+	b, _ = p.Read()
+	assert.Equal(t, []byte{0x04}, b)
+
+	// sends Ctrl-D only once
+	b, _ = p.Read()
+	assert.Equal(t, []byte("a"), b)
+}
@@ -0,0 +1,210 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/config"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+)
+
+// library is the command aliases and the saved queries, replaced by the loaded library at startup.
+var library = config.NewLibrary("")
+
+// Avoid to circular dependencies
+var (
+	doAliasFn   func(context.Context, bigtable.Client, ...string) error
+	doSaveFn    func(context.Context, bigtable.Client, ...string) error
+	doRunFn     func(context.Context, bigtable.Client, ...string) error
+	doProfileFn func(context.Context, bigtable.Client, ...string) error
+)
+
+func doAlias(ctx context.Context, client bigtable.Client, args ...string) error {
+	return doAliasFn(ctx, client, args...)
+}
+
+func doSave(ctx context.Context, client bigtable.Client, args ...string) error {
+	return doSaveFn(ctx, client, args...)
+}
+
+func doRun(ctx context.Context, client bigtable.Client, args ...string) error {
+	return doRunFn(ctx, client, args...)
+}
+
+func doProfile(ctx context.Context, client bigtable.Client, args ...string) error {
+	return doProfileFn(ctx, client, args...)
+}
+
+func init() {
+	doAliasFn = lazyDoAlias
+	doSaveFn = lazyDoSave
+	doRunFn = lazyDoRun
+	doProfileFn = lazyDoProfile
+}
+
+// resolveAlias replaces the alias of the first word with the command.
+func resolveAlias(line string) string {
+	name, rest := line, ""
+	if i := strings.Index(line, " "); i >= 0 {
+		name, rest = line[:i], line[i:]
+	}
+	if cmd, ok := library.Aliases[name]; ok {
+		return cmd + rest
+	}
+	return line
+}
+
+// lazyDoAlias sets the aliases, or prints all the aliases without the arguments.
+// An empty command removes the alias.
+func lazyDoAlias(ctx context.Context, client bigtable.Client, args ...string) error {
+	if len(args) == 0 {
+		printSorted(client, library.Aliases)
+		return nil
+	}
+
+	// the quoted command is split by the executor
+	arg := strings.Join(args, " ")
+	i := strings.Index(arg, "=")
+	if i < 0 || !validLibraryName(arg[:i]) {
+		return cbt.UsageError("Invalid args: alias <name>='<command>'")
+	}
+	name, cmd := arg[:i], unquote(arg[i+1:])
+	if _, ok := findCommand(name); ok {
+		return cbt.UsageError("Command %s can not be an alias", name)
+	}
+
+	if cmd == "" {
+		delete(library.Aliases, name)
+	} else {
+		library.Aliases[name] = cmd
+	}
+	return cbt.WrapError(library.Save())
+}
+
+// lazyDoSave saves the command as the named query.
+// The variables are saved without the expansion, and an empty command removes the query.
+func lazyDoSave(ctx context.Context, client bigtable.Client, args ...string) error {
+	args = rawArgs(ctx, args)
+	if len(args) < 2 || args[0] != "query" || !validLibraryName(args[1]) {
+		return cbt.UsageError("Invalid args: save query <name> <command> [args ...]")
+	}
+	name, cmd := args[1], strings.Join(args[2:], " ")
+
+	if cmd == "" {
+		delete(library.Queries, name)
+	} else {
+		if _, ok := findCommand(args[2]); !ok {
+			return cbt.UsageError("Unknown command: %s", args[2])
+		}
+		library.Queries[name] = cmd
+	}
+	return cbt.WrapError(library.Save())
+}
+
+// lazyDoRun runs the saved query with the additional arguments, or prints all the queries without the arguments.
+func lazyDoRun(ctx context.Context, client bigtable.Client, args ...string) error {
+	if len(args) == 0 {
+		printSorted(client, library.Queries)
+		return nil
+	}
+	line, err := queryLine(args)
+	if err != nil {
+		return err
+	}
+
+	e := &Executor{client: client}
+	return e.execute(ctx, line)
+}
+
+// queryLine returns the line of the saved query named by the first argument, followed by the additional arguments.
+func queryLine(args []string) (string, error) {
+	q, ok := library.Queries[args[0]]
+	if !ok {
+		return "", cbt.NotFoundError("Query not found: %s", args[0])
+	}
+	if strings.HasPrefix(q, "run ") {
+		return "", cbt.UsageError("Query %s can not run the other query", args[0])
+	}
+	return strings.Join(append([]string{q}, args[1:]...), " "), nil
+}
+
+// profileOptions is the options of the profile command
+var profileOptions = cbt.Options{
+	{Name: "instance", Description: "Instance of the profile"},
+	{Name: "project", Description: "Project of the profile, default is the current project"},
+}
+
+// lazyDoProfile sets the profile, or prints all the profiles without the arguments.
+// The profile without the options is removed.
+func lazyDoProfile(ctx context.Context, client bigtable.Client, args ...string) error {
+	if len(args) == 0 {
+		profiles := make(map[string]string, len(library.Profiles))
+		for n, p := range library.Profiles {
+			profiles[n] = p.Project + "/" + p.Instance
+		}
+		printSorted(client, profiles)
+		return nil
+	}
+	name := args[0]
+	if !validLibraryName(name) {
+		return cbt.UsageError("Invalid args: profile <name> [instance=<instance>] [project=<project>]")
+	}
+	if len(args) == 1 {
+		delete(library.Profiles, name)
+		return cbt.WrapError(library.Save())
+	}
+
+	parsed, err := profileOptions.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+	p := config.Profile{
+		Project:  parsed["project"],
+		Instance: parsed["instance"],
+	}
+	if p.Project == "" {
+		p.Project = currentSession.project
+	}
+	if p.Instance == "" {
+		return cbt.UsageError("Missing the instance of the profile %s", name)
+	}
+	library.Profiles[name] = p
+	return cbt.WrapError(library.Save())
+}
+
+// connectProfile returns the function to connect the profile in the library, used for "<profile>:<table>".
+func connectProfile(clients *bigtable.Clients) func(string) (bigtable.Client, error) {
+	return func(name string) (bigtable.Client, error) {
+		p, ok := library.Profiles[name]
+		if !ok {
+			return nil, cbt.NotFoundError("Profile not found: %s", name)
+		}
+		return clients.Get(bigtable.Profile{Project: p.Project, Instance: p.Instance})
+	}
+}
+
+func printSorted(client bigtable.Client, m map[string]string) {
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintf(client.OutStream(), "%s=%s\n", n, m[n])
+	}
+}
+
+// validLibraryName reports whether the name is available for the alias and the query, allows "-" unlike the variable.
+func validLibraryName(name string) bool {
+	return validName(strings.Replace(name, "-", "_", -1))
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,171 @@
+package interactive
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/config"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+)
+
+func TestDoAlias(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	library = config.NewLibrary("")
+	defer func() { library = config.NewLibrary("") }()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+
+	// split by the executor
+	assert.NoError(t, doAlias(context.Background(), mockClient, "rr='read", "users", "decode=int'"))
+	assert.NoError(t, doAlias(context.Background(), mockClient, "ls2=ls"))
+	assert.NoError(t, doAlias(context.Background(), mockClient))
+	assert.Equal(t, "ls2=ls\nrr=read users decode=int\n", buf.String())
+
+	assert.NoError(t, doAlias(context.Background(), mockClient, "ls2="))
+	assert.Equal(t, map[string]string{"rr": "read users decode=int"}, library.Aliases)
+
+	err := doAlias(context.Background(), mockClient, "read=ls")
+	assert.Equal(t, cbt.KindUsage, cbt.KindOf(err))
+	err = doAlias(context.Background(), mockClient, "rr")
+	assert.Equal(t, cbt.KindUsage, cbt.KindOf(err))
+}
+
+func TestResolveAlias(t *testing.T) {
+	library = config.NewLibrary("")
+	defer func() { library = config.NewLibrary("") }()
+	library.Aliases["rr"] = "read users decode=int"
+
+	assert.Equal(t, "read users decode=int", resolveAlias("rr"))
+	assert.Equal(t, "read users decode=int count=1", resolveAlias("rr count=1"))
+	assert.Equal(t, "rrr count=1", resolveAlias("rrr count=1"))
+}
+
+func TestDoSaveAndRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	library = config.NewLibrary("")
+	defer func() { library = config.NewLibrary("") }()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+
+	assert.NoError(t, doSave(context.Background(), mockClient, "query", "hot-users", "count", "users", "prefix=2##"))
+	assert.Equal(t, map[string]string{"hot-users": "count users prefix=2##"}, library.Queries)
+
+	err := doSave(context.Background(), mockClient, "query", "bad", "unknown")
+	assert.Equal(t, cbt.KindUsage, cbt.KindOf(err))
+	err = doSave(context.Background(), mockClient, "alias", "bad", "ls")
+	assert.Equal(t, cbt.KindUsage, cbt.KindOf(err))
+
+	mockClient.EXPECT().Count(gomock.Any(), "users", gomock.Any(), gomock.Any()).Return(3, nil)
+	assert.NoError(t, doRun(context.Background(), mockClient, "hot-users", "family=d"))
+	assert.Equal(t, "3\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, doRun(context.Background(), mockClient))
+	assert.Equal(t, "hot-users=count users prefix=2##\n", buf.String())
+
+	err = doRun(context.Background(), mockClient, "unknown")
+	assert.Equal(t, cbt.KindNotFound, cbt.KindOf(err))
+
+	assert.NoError(t, doSave(context.Background(), mockClient, "query", "hot-users"))
+	assert.Empty(t, library.Queries)
+}
+
+func TestDoSaveVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	library = config.NewLibrary("")
+	defer func() { library = config.NewLibrary("") }()
+	defer func() { currentSession = newSession() }()
+	currentSession.vars = map[string]string{"p": "2##"}
+
+	mockClient := bt.NewMockClient(ctrl)
+	e := &Executor{client: mockClient}
+	assert.NoError(t, e.execute(context.Background(), "save query hot-users count users prefix=$p"))
+	assert.Equal(t, map[string]string{"hot-users": "count users prefix=$p"}, library.Queries)
+}
+
+func TestDoProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	library = config.NewLibrary("")
+	defer func() { library = config.NewLibrary("") }()
+	currentSession.project = "test-project"
+	defer func() { currentSession.project = "" }()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+
+	assert.NoError(t, doProfile(context.Background(), mockClient, "staging", "instance=staging-instance"))
+	assert.NoError(t, doProfile(context.Background(), mockClient, "prod", "instance=prod-instance", "project=prod-project"))
+	assert.NoError(t, doProfile(context.Background(), mockClient))
+	assert.Equal(t, "prod=prod-project/prod-instance\nstaging=test-project/staging-instance\n", buf.String())
+
+	assert.NoError(t, doProfile(context.Background(), mockClient, "prod"))
+	assert.Equal(t, map[string]config.Profile{"staging": {Project: "test-project", Instance: "staging-instance"}}, library.Profiles)
+
+	err := doProfile(context.Background(), mockClient, "dev", "project=dev-project")
+	assert.Equal(t, cbt.KindUsage, cbt.KindOf(err))
+	err = doProfile(context.Background(), mockClient, "dev", "zone=asia")
+	assert.Equal(t, cbt.KindUsage, cbt.KindOf(err))
+}
+
+func TestConnectProfile(t *testing.T) {
+	library = config.NewLibrary("")
+	defer func() { library = config.NewLibrary("") }()
+	library.Profiles["staging"] = config.Profile{Project: "test-project", Instance: "staging-instance"}
+
+	// the connection is not used
+	defer os.Setenv("BIGTABLE_EMULATOR_HOST", os.Getenv("BIGTABLE_EMULATOR_HOST"))
+	os.Setenv("BIGTABLE_EMULATOR_HOST", "127.0.0.1:1")
+	clients := bt.NewClients()
+	defer clients.Close()
+	connect := connectProfile(clients)
+
+	c, err := connect("staging")
+	assert.NoError(t, err)
+	same, err := connect("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, c, same)
+
+	_, err = connect("prod")
+	assert.Equal(t, cbt.KindNotFound, cbt.KindOf(err))
+}
+
+func TestCompleteWithLibrary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	library = config.NewLibrary("")
+	defer func() { library = config.NewLibrary("") }()
+	library.Aliases["rr"] = "read users decode=int"
+	library.Queries["hot-users"] = "read users prefix=2##"
+
+	cases := []struct {
+		args   []string
+		expect []string
+	}{
+		{[]string{"r"}, []string{"read", "run", "refresh", "rr"}},
+		{[]string{"rr", "cou"}, []string{"count"}},
+		{[]string{"run", "h"}, []string{"hot-users"}},
+	}
+	for _, c := range cases {
+		completer := &Completer{client: bt.NewMockClient(ctrl)}
+
+		actual := []string{}
+		for _, s := range completer.completeWithArguments(c.args...) {
+			actual = append(actual, s.Text)
+		}
+		assert.Equal(t, c.expect, actual)
+	}
+}
@@ -0,0 +1,176 @@
+package interactive
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+)
+
+// pagerDelay is the time to wait for the output to fill the terminal.
+// The output slower than this is written to the terminal directly to show it incrementally.
+const pagerDelay = 500 * time.Millisecond
+
+// pagerInput is the key input of the built-in pager
+var pagerInput io.Reader = os.Stdin
+
+type pagerState int
+
+const (
+	// buffering until the output exceeds the terminal height
+	pagerBuffering pagerState = iota
+	// writing to the terminal without paging
+	pagerPassing
+	// writing to the $PAGER command
+	pagerExternal
+	// paging by the built-in pager
+	pagerBuiltin
+	// discarding the rest of the output
+	pagerQuit
+)
+
+// pager pages the output exceeds the terminal height through the $PAGER command or the built-in pager
+type pager struct {
+	out    io.Writer
+	errOut io.Writer
+	in     io.Reader
+	height int
+	// command is the $PAGER command, uses the built-in pager when empty
+	command string
+	// cancel stops the command when the pager quits
+	cancel func()
+
+	mu    sync.Mutex
+	state pagerState
+	buf   bytes.Buffer
+	lines int
+	timer *time.Timer
+	pipe  io.WriteCloser
+}
+
+func newPager(out, errOut io.Writer, height int, command string, cancel func()) *pager {
+	return &pager{
+		out:     out,
+		errOut:  errOut,
+		in:      pagerInput,
+		height:  height,
+		command: command,
+		cancel:  cancel,
+	}
+}
+
+// Write buffers the output while it fits in the terminal, and starts paging when exceeded.
+// Always reports success not to stop the command, and discards the output after the pager quits.
+func (p *pager) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch p.state {
+	case pagerBuffering:
+		if p.timer == nil {
+			p.timer = time.AfterFunc(pagerDelay, p.pass)
+		}
+		p.buf.Write(b)
+		p.lines += bytes.Count(b, []byte("\n"))
+		if p.lines < p.height-1 {
+			return len(b), nil
+		}
+		p.timer.Stop()
+		p.start()
+	case pagerPassing:
+		p.out.Write(b)
+	case pagerExternal:
+		if _, err := p.pipe.Write(b); err != nil {
+			// the pager command exited
+			p.quit()
+		}
+	case pagerBuiltin:
+		p.page(b)
+	}
+	return len(b), nil
+}
+
+// Close flushes the buffered output, or waits for the pager command to exit.
+func (p *pager) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	if p.state == pagerBuffering {
+		p.out.Write(p.buf.Bytes())
+		p.buf.Reset()
+	}
+	if p.pipe != nil {
+		return p.pipe.Close()
+	}
+	return nil
+}
+
+// pass writes the buffered output to the terminal, and stops paging the slow output.
+func (p *pager) pass() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.state != pagerBuffering {
+		return
+	}
+	p.state = pagerPassing
+	p.out.Write(p.buf.Bytes())
+	p.buf.Reset()
+}
+
+// start starts paging the buffered output, falls back to the built-in pager when the command fails.
+func (p *pager) start() {
+	data := p.buf.Bytes()
+	p.buf.Reset()
+	p.lines = 0
+
+	if p.command != "" {
+		rd := &redirect{pipe: p.command}
+		w, err := rd.open(p.out, p.errOut)
+		if err == nil {
+			p.state = pagerExternal
+			p.pipe = w
+			if _, err := w.Write(data); err != nil {
+				p.quit()
+			}
+			return
+		}
+	}
+	p.state = pagerBuiltin
+	p.page(data)
+}
+
+// page writes the lines, and waits for the key input each time the terminal is filled.
+func (p *pager) page(b []byte) {
+	for len(b) > 0 && p.state == pagerBuiltin {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			p.out.Write(b)
+			return
+		}
+		p.out.Write(b[:i+1])
+		b = b[i+1:]
+
+		p.lines++
+		if p.lines < p.height-1 {
+			continue
+		}
+		p.lines = 0
+		if !cbt.WaitMore(p.in, p.out) {
+			p.quit()
+		}
+	}
+}
+
+func (p *pager) quit() {
+	p.state = pagerQuit
+	if p.cancel != nil {
+		p.cancel()
+	}
+}
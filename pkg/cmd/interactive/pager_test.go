@@ -0,0 +1,85 @@
+package interactive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+)
+
+func writeLines(p *pager, n int) {
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(p, "%d\n", i)
+	}
+}
+
+func TestPagerShortOutput(t *testing.T) {
+	var out bytes.Buffer
+	p := newPager(&out, &out, 5, "", nil)
+
+	writeLines(p, 3)
+	assert.Equal(t, "", out.String())
+	assert.NoError(t, p.Close())
+	assert.Equal(t, "1\n2\n3\n", out.String())
+}
+
+func TestPagerBuiltin(t *testing.T) {
+	var out bytes.Buffer
+	canceled := false
+	p := newPager(&out, &out, 3, "", func() { canceled = true })
+	p.in = strings.NewReader("\nq\n")
+
+	writeLines(p, 10)
+	assert.NoError(t, p.Close())
+	more := "-- More -- (Enter: next page, q: quit) "
+	assert.Equal(t, "1\n2\n"+more+"3\n4\n"+more, out.String())
+	assert.True(t, canceled)
+}
+
+func TestPagerExternal(t *testing.T) {
+	var out bytes.Buffer
+	p := newPager(&out, &out, 3, "sed 's/^/> /'", nil)
+
+	writeLines(p, 4)
+	assert.NoError(t, p.Close())
+	assert.Equal(t, "> 1\n> 2\n> 3\n> 4\n", out.String())
+}
+
+func TestPagerSlowOutput(t *testing.T) {
+	var out bytes.Buffer
+	p := newPager(&out, &out, 3, "", nil)
+
+	writeLines(p, 1)
+	// the delay is passed
+	p.pass()
+	assert.Equal(t, "1\n", out.String())
+	writeLines(p, 4)
+	assert.NoError(t, p.Close())
+	assert.Equal(t, "1\n1\n2\n3\n4\n", out.String())
+}
+
+func TestDoSet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func() { currentSession = newSession() }()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+
+	assert.NoError(t, doSet(context.Background(), mockClient, "pager", "off"))
+	assert.False(t, currentSession.pager)
+	assert.NoError(t, doSet(context.Background(), mockClient))
+	assert.Equal(t, "pager\toff\ntiming\toff\n", buf.String())
+
+	err := doSet(context.Background(), mockClient, "pager", "yes")
+	assert.Equal(t, cbt.KindUsage, cbt.KindOf(err))
+	err = doSet(context.Background(), mockClient, "unknown", "on")
+	assert.Equal(t, cbt.KindUsage, cbt.KindOf(err))
+}
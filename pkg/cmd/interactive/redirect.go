@@ -0,0 +1,93 @@
+package interactive
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+)
+
+// redirect is the destination of the command output given by ">", ">>" or "|"
+type redirect struct {
+	// path is the file to write, or to append when appending is true
+	path      string
+	appending bool
+	// pipe is the shell command to read the output
+	pipe string
+}
+
+// splitRedirect splits the arguments before the redirection and the destination.
+// The redirection operators must be separated by spaces, returns nil when the output is not redirected.
+func splitRedirect(args []string) ([]string, *redirect, error) {
+	for i, a := range args {
+		switch a {
+		case "|":
+			pipe := strings.TrimSpace(strings.Join(args[i+1:], " "))
+			if pipe == "" {
+				return nil, nil, cbt.UsageError("Invalid redirect: missing command after |")
+			}
+			return args[:i], &redirect{pipe: pipe}, nil
+		case ">", ">>":
+			if len(args) != i+2 {
+				return nil, nil, cbt.UsageError("Invalid redirect: %s <file>", a)
+			}
+			return args[:i], &redirect{path: args[i+1], appending: a == ">>"}, nil
+		}
+	}
+	return args, nil, nil
+}
+
+// open returns the writer to the destination. The output of the pipe goes to out and errOut.
+func (r *redirect) open(out, errOut io.Writer) (io.WriteCloser, error) {
+	if r.pipe == "" {
+		flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if r.appending {
+			flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		return os.OpenFile(r.path, flag, 0644)
+	}
+
+	cmd := shellCommand(r.pipe)
+	cmd.Stdout = out
+	cmd.Stderr = errOut
+	w, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &pipeWriter{WriteCloser: w, cmd: cmd}, nil
+}
+
+// pipeWriter writes to the stdin of the command, and waits for the command to exit on close
+type pipeWriter struct {
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+// Close closes the stdin and waits for the command.
+// The exit status is ignored as well as the shell, the command prints the reason by itself.
+func (p *pipeWriter) Close() error {
+	p.WriteCloser.Close()
+	if err := p.cmd.Wait(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return exec.Command(shell, "-c", command)
+}
@@ -0,0 +1,101 @@
+package interactive
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+func TestSplitRedirect(t *testing.T) {
+	cases := []struct {
+		args       []string
+		expectArgs []string
+		expect     *redirect
+		err        bool
+	}{
+		{
+			[]string{"ls"},
+			[]string{"ls"},
+			nil,
+			false,
+		},
+		{
+			[]string{"read", "users", "regex=a>b"},
+			[]string{"read", "users", "regex=a>b"},
+			nil,
+			false,
+		},
+		{
+			[]string{"read", "users", ">", "out.txt"},
+			[]string{"read", "users"},
+			&redirect{path: "out.txt"},
+			false,
+		},
+		{
+			[]string{"read", "users", ">>", "out.txt"},
+			[]string{"read", "users"},
+			&redirect{path: "out.txt", appending: true},
+			false,
+		},
+		{
+			[]string{"read", "users", "|", "grep", "madoka", "|", "wc", "-l"},
+			[]string{"read", "users"},
+			&redirect{pipe: "grep madoka | wc -l"},
+			false,
+		},
+		{
+			[]string{"read", "users", ">"},
+			nil,
+			nil,
+			true,
+		},
+		{
+			[]string{"read", "users", "|", ""},
+			nil,
+			nil,
+			true,
+		},
+	}
+	for _, c := range cases {
+		args, rd, err := splitRedirect(c.args)
+		assert.Equal(t, c.err, err != nil, "%v: %v", c.args, err)
+		assert.Equal(t, c.expectArgs, args)
+		assert.Equal(t, c.expect, rd)
+	}
+}
+
+func TestExecutorWithRedirect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "out.txt")
+
+	mockClient := bt.NewMockClient(ctrl)
+	var out, errOut bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&out).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&errOut).AnyTimes()
+	mockClient.EXPECT().Tables(gomock.Any()).Return([]string{"articles", "users"}, nil).Times(3)
+
+	e := &Executor{client: mockClient}
+	assert.NoError(t, e.execute(context.Background(), "ls > "+path))
+	assert.NoError(t, e.execute(context.Background(), "ls >> "+path))
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "articles\nusers\narticles\nusers\n", string(data))
+	assert.Equal(t, "", out.String())
+
+	assert.NoError(t, e.execute(context.Background(), "ls | grep user"))
+	assert.Equal(t, "users\n", out.String())
+}
@@ -0,0 +1,199 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+)
+
+// currentSession is the state of the interactive session.
+var currentSession = newSession()
+
+// session holds the variables and the default table of the interactive session
+type session struct {
+	project  string
+	instance string
+	// table is the default table, used when the table argument is omitted
+	table string
+	vars  map[string]string
+
+	// pager represents the long output is paged
+	pager bool
+	// timing represents the time and the statistics are printed after each command
+	timing bool
+}
+
+func newSession() *session {
+	return &session{
+		vars:  map[string]string{},
+		pager: true,
+	}
+}
+
+// settings returns the switches of the session to be changed by "set".
+func (s *session) settings() map[string]*bool {
+	return map[string]*bool{
+		"pager":  &s.pager,
+		"timing": &s.timing,
+	}
+}
+
+// prefix returns the prompt string with the current instance and table.
+func (s *session) prefix() (string, bool) {
+	if s.table == "" {
+		return s.instance + "> ", true
+	}
+	return fmt.Sprintf("%s:%s> ", s.instance, s.table), true
+}
+
+// expand replaces "$name" and "${name}" with the variables, and "$$" with "$".
+// "$" not followed by a name is left as is to keep the regex such as "^a$".
+func (s *session) expand(line string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] != '$' || i+1 == len(line) {
+			b.WriteByte(line[i])
+			continue
+		}
+
+		var name string
+		switch next := line[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+			continue
+		case next == '{':
+			end := strings.IndexByte(line[i+2:], '}')
+			if end < 0 {
+				return "", cbt.UsageError("Invalid variable: %s", line[i:])
+			}
+			name = line[i+2 : i+2+end]
+			i += end + 2
+		case isNameChar(next):
+			j := i + 1
+			for j < len(line) && isNameChar(line[j]) {
+				j++
+			}
+			name = line[i+1 : j]
+			i = j - 1
+		default:
+			b.WriteByte('$')
+			continue
+		}
+
+		v, ok := s.vars[name]
+		if !ok {
+			return "", cbt.UsageError("Undefined variable: %s", name)
+		}
+		b.WriteString(v)
+	}
+	return b.String(), nil
+}
+
+// withTable inserts the default table to the arguments when the table is omitted.
+// The table is regarded as omitted when the positional arguments are fewer than the command requires.
+func (s *session) withTable(cmd Command, args []string) []string {
+	if s.table == "" || !cmd.DefaultTable {
+		return args
+	}
+	if positional, _ := cmd.Options.Split(args); len(positional) >= len(cmd.Args) {
+		return args
+	}
+	return append([]string{s.table}, args...)
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// doLet sets the variables, or prints all the variables without the arguments.
+// An empty value removes the variable.
+func doLet(ctx context.Context, client bigtable.Client, args ...string) error {
+	if len(args) == 0 {
+		printSorted(client, currentSession.vars)
+		return nil
+	}
+
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i < 0 || !validName(arg[:i]) {
+			return cbt.UsageError("Invalid args: let <name>=<value>")
+		}
+		if arg[i+1:] == "" {
+			delete(currentSession.vars, arg[:i])
+			continue
+		}
+		currentSession.vars[arg[:i]] = arg[i+1:]
+	}
+	return nil
+}
+
+// doSet turns on or off the setting, or prints all the settings without the arguments.
+func doSet(ctx context.Context, client bigtable.Client, args ...string) error {
+	settings := currentSession.settings()
+	if len(args) == 0 {
+		names := make([]string, 0, len(settings))
+		for n := range settings {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			fmt.Fprintf(client.OutStream(), "%s\t%s\n", n, onOff(*settings[n]))
+		}
+		return nil
+	}
+
+	if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+		return cbt.UsageError("Invalid args: set <name> <on|off>")
+	}
+	v, ok := settings[args[0]]
+	if !ok {
+		return cbt.UsageError("Unknown setting: %s", args[0])
+	}
+	*v = args[1] == "on"
+	return nil
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// doUse sets the default table, or unsets it without the arguments.
+func doUse(ctx context.Context, client bigtable.Client, args ...string) error {
+	if len(args) == 0 {
+		currentSession.table = ""
+		return nil
+	}
+	table := args[0]
+
+	tables, err := client.Tables(ctx)
+	if err != nil {
+		return cbt.WrapError(err)
+	}
+	for _, t := range tables {
+		if t == table {
+			currentSession.table = table
+			return nil
+		}
+	}
+	return cbt.NotFoundError("Table not found: %s", table)
+}
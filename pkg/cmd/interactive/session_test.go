@@ -0,0 +1,137 @@
+package interactive
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+)
+
+func TestSessionExpand(t *testing.T) {
+	s := newSession()
+	s.vars = map[string]string{"t": "users", "p": "2##"}
+
+	cases := []struct {
+		input  string
+		expect string
+		err    bool
+	}{
+		{"read $t prefix=${p}1", "read users prefix=2##1", false},
+		{"read $t regex=^a$", "read users regex=^a$", false},
+		{"read $t regex=^(a|b)$ value=$$p", "read users regex=^(a|b)$ value=$p", false},
+		{"read $table", "", true},
+		{"read ${t", "", true},
+	}
+	for _, c := range cases {
+		actual, err := s.expand(c.input)
+		assert.Equal(t, c.err, err != nil, "%s: %v", c.input, err)
+		assert.Equal(t, c.expect, actual)
+	}
+}
+
+func TestSessionWithTable(t *testing.T) {
+	read, _ := findCommand("read")
+	lookup, _ := findCommand("lookup")
+	use, _ := findCommand("use")
+
+	cases := []struct {
+		table  string
+		cmd    Command
+		args   []string
+		expect []string
+	}{
+		{"", read, []string{"prefix=1"}, []string{"prefix=1"}},
+		{"users", read, []string{"prefix=1"}, []string{"users", "prefix=1"}},
+		{"users", read, []string{"articles", "prefix=1"}, []string{"articles", "prefix=1"}},
+		{"users", lookup, []string{"1"}, []string{"users", "1"}},
+		{"users", lookup, []string{"articles", "1"}, []string{"articles", "1"}},
+		// the row may contain "="
+		{"users", lookup, []string{"articles", "a=b"}, []string{"articles", "a=b"}},
+		{"users", lookup, []string{"a=b", "version=1"}, []string{"users", "a=b", "version=1"}},
+		{"users", use, []string{}, []string{}},
+	}
+	for _, c := range cases {
+		s := newSession()
+		s.table = c.table
+		assert.Equal(t, c.expect, s.withTable(c.cmd, c.args))
+	}
+}
+
+func TestSessionPrefix(t *testing.T) {
+	s := newSession()
+	s.instance = "dev"
+	p, _ := s.prefix()
+	assert.Equal(t, "dev> ", p)
+
+	s.table = "users"
+	p, _ = s.prefix()
+	assert.Equal(t, "dev:users> ", p)
+}
+
+func TestDoLet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func() { currentSession = newSession() }()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+
+	assert.NoError(t, doLet(context.Background(), mockClient, "t=users", "p=2##"))
+	assert.NoError(t, doLet(context.Background(), mockClient))
+	assert.Equal(t, "p=2##\nt=users\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, doLet(context.Background(), mockClient, "p="))
+	assert.NoError(t, doLet(context.Background(), mockClient))
+	assert.Equal(t, "t=users\n", buf.String())
+
+	err := doLet(context.Background(), mockClient, "a-b=1")
+	assert.Equal(t, cbt.KindUsage, cbt.KindOf(err))
+}
+
+func TestDoUse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func() { currentSession = newSession() }()
+
+	mockClient := bt.NewMockClient(ctrl)
+	mockClient.EXPECT().Tables(gomock.Any()).Return([]string{"articles", "users"}, nil).Times(2)
+
+	assert.NoError(t, doUse(context.Background(), mockClient, "users"))
+	assert.Equal(t, "users", currentSession.table)
+
+	err := doUse(context.Background(), mockClient, "unknown")
+	assert.Equal(t, cbt.KindNotFound, cbt.KindOf(err))
+	assert.Equal(t, "users", currentSession.table)
+
+	assert.NoError(t, doUse(context.Background(), mockClient))
+	assert.Equal(t, "", currentSession.table)
+}
+
+func TestExecutorWithSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func() { currentSession = newSession() }()
+
+	currentSession.table = "users"
+	currentSession.vars["p"] = "2##"
+
+	mockClient := bt.NewMockClient(ctrl)
+	var out bytes.Buffer
+	h := &history{}
+	mockClient.EXPECT().OutStream().Return(&out).AnyTimes()
+	mockClient.EXPECT().Count(gomock.Any(), "users", gomock.Any(), gomock.Any()).Return(3, nil)
+
+	e := &Executor{client: mockClient, history: h}
+	assert.NoError(t, e.execute(context.Background(), "count prefix=$p"))
+	assert.Equal(t, "3\n", out.String())
+	assert.Equal(t, []string{"count prefix=$p"}, h.entries)
+
+	err := e.execute(context.Background(), "count prefix=$q")
+	assert.EqualError(t, err, "Undefined variable: q")
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package interactive
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalHeight returns the rows of the terminal, or 0 when the output is not a terminal. Replaced in the tests.
+var terminalHeight = func() int {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(ws.Row)
+}
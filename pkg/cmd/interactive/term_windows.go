@@ -0,0 +1,19 @@
+//go:build windows
+// +build windows
+
+package interactive
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// terminalHeight returns the rows of the terminal, or 0 when the output is not a terminal. Replaced in the tests.
+var terminalHeight = func() int {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(os.Stdout.Fd()), &info); err != nil {
+		return 0
+	}
+	return int(info.Window.Bottom - info.Window.Top + 1)
+}
@@ -0,0 +1,46 @@
+package interactive
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/takashabe/btcli/pkg/bigtable"
+)
+
+// timingCommand toggles the timing, like psql
+const timingCommand = `\timing`
+
+// resolveTiming replaces "\timing [on|off]" with the set command, toggles the timing without the argument.
+func resolveTiming(line string) string {
+	args := strings.Fields(line)
+	if len(args) == 0 || args[0] != timingCommand {
+		return line
+	}
+	if len(args) == 1 {
+		return "set timing " + onOff(!currentSession.timing)
+	}
+	return "set timing " + strings.Join(args[1:], " ")
+}
+
+// stopwatch measures the elapsed time and the statistics of the requests
+type stopwatch struct {
+	stats func() bigtable.Stats
+	start time.Time
+	base  bigtable.Stats
+}
+
+func startStopwatch(stats func() bigtable.Stats) *stopwatch {
+	return &stopwatch{
+		stats: stats,
+		start: time.Now(),
+		base:  stats(),
+	}
+}
+
+// print prints the elapsed time and the statistics since the stopwatch started.
+func (s *stopwatch) print(w io.Writer) {
+	elapsed := time.Since(s.start).Round(time.Microsecond)
+	fmt.Fprintf(w, "Time: %v (%v)\n", elapsed, s.stats().Sub(s.base))
+}
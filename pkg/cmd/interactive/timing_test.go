@@ -0,0 +1,63 @@
+package interactive
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+func TestResolveTiming(t *testing.T) {
+	defer func() { currentSession = newSession() }()
+
+	cases := []struct {
+		line   string
+		timing bool
+		expect string
+	}{
+		{`\timing on`, false, "set timing on"},
+		{`\timing  off`, true, "set timing off"},
+		{`\timing`, false, "set timing on"},
+		{`\timing`, true, "set timing off"},
+		{"read users", true, "read users"},
+	}
+	for _, c := range cases {
+		currentSession.timing = c.timing
+		assert.Equal(t, c.expect, resolveTiming(c.line))
+	}
+}
+
+func TestExecutorTiming(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func() { currentSession = newSession() }()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var out, errOut bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&out).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&errOut).AnyTimes()
+	gomock.InOrder(
+		mockClient.EXPECT().Stats().Return(bt.Stats{Rows: 1, Cells: 2, Bytes: 100}),
+		mockClient.EXPECT().Stats().Return(bt.Stats{Rows: 4, Cells: 8, Bytes: 400, Retries: 1}),
+		// started by turning off the timing, but not printed
+		mockClient.EXPECT().Stats().Return(bt.Stats{}),
+	)
+	mockClient.EXPECT().Count(gomock.Any(), "users", gomock.Any(), gomock.Any()).Return(3, nil)
+
+	e := &Executor{client: mockClient}
+	e.Do(`\timing on`)
+	assert.True(t, currentSession.timing)
+	assert.Empty(t, errOut.String())
+
+	e.Do("count users")
+	assert.Equal(t, "3\n", out.String())
+	assert.Contains(t, errOut.String(), "(rows: 3, cells: 6, bytes: 300, retries: 1)\n")
+	assert.Regexp(t, "^Time: ", errOut.String())
+
+	errOut.Reset()
+	e.Do(`\timing`)
+	assert.False(t, currentSession.timing)
+	assert.Empty(t, errOut.String())
+}
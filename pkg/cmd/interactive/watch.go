@@ -0,0 +1,287 @@
+package interactive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+)
+
+// escape sequences to redraw the screen and to highlight the changed cells
+const (
+	clearScreen  = "\x1b[H\x1b[2J"
+	colorAdded   = "\x1b[32m"
+	colorChanged = "\x1b[33m"
+	colorRemoved = "\x1b[31m"
+)
+
+// Avoid to circular dependencies
+var (
+	doWatchFn func(context.Context, bt.Client, ...string) error
+)
+
+func doWatch(ctx context.Context, client bt.Client, args ...string) error {
+	return doWatchFn(ctx, client, args...)
+}
+
+func init() {
+	doWatchFn = lazyDoWatch
+}
+
+// lazyDoWatch runs the command every interval until Ctrl-C, and redraws the output.
+// The cells added, removed or changed from the previous run are highlighted.
+func lazyDoWatch(ctx context.Context, client bt.Client, args ...string) error {
+	// the command is parsed from the arguments before the expansion, not to expand the variables twice
+	raw := rawArgs(ctx, args)
+	if len(args) < 2 || len(raw) < 2 {
+		return cbt.UsageError("Invalid args: watch <interval> <command> [args ...]")
+	}
+	interval, err := time.ParseDuration(args[0])
+	if err != nil || interval <= 0 {
+		return cbt.UsageError("Invalid interval: %s", args[0])
+	}
+	line := strings.Join(raw[1:], " ")
+	st, err := parse(line)
+	if err != nil {
+		return err
+	}
+	if st.cmd.Name == "run" && len(st.raw) > 0 {
+		// watch the saved query itself
+		q, err := queryLine(st.raw)
+		if err != nil {
+			return err
+		}
+		if st, err = parse(q); err != nil {
+			return err
+		}
+	}
+	// only the commands reading the tables, not to change the state on every run
+	if !(st.cmd.Rows || st.cmd.Reads) || st.cmd.Writes || st.cmd.Continuous {
+		return cbt.UsageError("Command %s can not be watched", st.cmd.Name)
+	}
+
+	ctx, stop := cbt.WithInterrupt(ctx)
+	defer stop()
+
+	w := &watcher{
+		header: fmt.Sprintf("Every %v: %s", interval, line),
+		st:     st,
+	}
+	for {
+		if err := w.run(ctx, client); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// watcher runs the statement and redraws the output with the changes from the previous run
+type watcher struct {
+	header string
+	st     *statement
+
+	// prev is the rows of the previous run, nil before the first run
+	prev []*bt.Row
+}
+
+// run runs the statement once and redraws the screen.
+// Returns only the usage error, the other errors are shown on the screen and retried at the next run.
+func (w *watcher) run(ctx context.Context, client bt.Client) error {
+	capture := &captureClient{Client: client, rows: []*bt.Row{}}
+	err := w.st.cmd.Runner(context.WithValue(ctx, statementKey{}, w.st), capture, w.st.args...)
+	if ctx.Err() != nil {
+		// interrupted while running
+		return nil
+	}
+	if cbt.KindOf(err) == cbt.KindUsage {
+		return err
+	}
+
+	var frame bytes.Buffer
+	fmt.Fprintf(&frame, "%s%s\t%s\n\n", clearScreen, w.header, time.Now().Format("2006/01/02-15:04:05"))
+	if w.st.cmd.Rows {
+		w.render(&frame, capture.rows)
+	} else {
+		frame.Write(capture.out.Bytes())
+	}
+	if err != nil {
+		printError(&frame, err)
+	}
+	client.OutStream().Write(frame.Bytes())
+
+	// compare with the last succeeded run, the missing row is regarded as removed
+	if w.st.cmd.Rows && (err == nil || cbt.KindOf(err) == cbt.KindNotFound) {
+		w.prev = capture.rows
+	}
+	return nil
+}
+
+// render prints the rows with the removed cells, highlighting the changes from the previous rows.
+func (w *watcher) render(out io.Writer, rows []*bt.Row) {
+	colors, removed := diffRows(w.prev, rows)
+
+	merged := make([]*bt.Row, 0, len(rows)+len(removed))
+	for _, r := range rows {
+		cs := append(r.Columns[:len(r.Columns):len(r.Columns)], removed[r.Key]...)
+		merged = append(merged, &bt.Row{Key: r.Key, Columns: cs})
+		delete(removed, r.Key)
+	}
+	for key, cs := range removed {
+		merged = append(merged, &bt.Row{Key: key, Columns: cs})
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Key < merged[j].Key
+	})
+
+	parsed, _ := w.st.cmd.Options.Parse(optionArgs(w.st.args))
+	p := cbt.NewPrinter(out, parsed)
+	p.Highlight = func(key string, c *bt.Column) string {
+		return colors[newCellID(key, c)]
+	}
+	p.PrintRows(merged)
+}
+
+// cellID identifies the cell by the row key, the column and the version
+type cellID struct {
+	key       string
+	qualifier string
+	version   int64
+}
+
+func newCellID(key string, c *bt.Column) cellID {
+	return cellID{key: key, qualifier: c.Qualifier, version: c.Version.UnixNano()}
+}
+
+// diffRows compares the cells of the rows with the previous rows.
+// Returns the colors of the added, changed and removed cells, and the removed cells by the row key.
+// A new version of the removed column, e.g. read with version=1, is regarded as changed.
+func diffRows(prev, rows []*bt.Row) (map[cellID]string, map[string][]*bt.Column) {
+	colors := map[cellID]string{}
+	removed := map[string][]*bt.Column{}
+	if prev == nil {
+		return colors, removed
+	}
+
+	prevCells := map[cellID]*bt.Column{}
+	for _, r := range prev {
+		for _, c := range r.Columns {
+			prevCells[newCellID(r.Key, c)] = c
+		}
+	}
+	cells := map[cellID]bool{}
+	var added []cellID
+	for _, r := range rows {
+		for _, c := range r.Columns {
+			id := newCellID(r.Key, c)
+			cells[id] = true
+			p, ok := prevCells[id]
+			switch {
+			case !ok:
+				added = append(added, id)
+				colors[id] = colorAdded
+			case !bytes.Equal(p.Value, c.Value):
+				colors[id] = colorChanged
+			}
+		}
+	}
+	for _, r := range prev {
+		for _, c := range r.Columns {
+			if id := newCellID(r.Key, c); !cells[id] {
+				removed[r.Key] = append(removed[r.Key], c)
+				colors[id] = colorRemoved
+			}
+		}
+	}
+
+	for _, id := range added {
+		cs := removed[id.key]
+		for i, c := range cs {
+			if c.Qualifier != id.qualifier {
+				continue
+			}
+			colors[id] = colorChanged
+			delete(colors, newCellID(id.key, c))
+			removed[id.key] = append(cs[:i:i], cs[i+1:]...)
+			if len(removed[id.key]) == 0 {
+				delete(removed, id.key)
+			}
+			break
+		}
+	}
+	return colors, removed
+}
+
+// optionArgs returns the "key=value" arguments.
+func optionArgs(args []string) []string {
+	var opts []string
+	for _, a := range args {
+		if strings.Contains(a, "=") {
+			opts = append(opts, a)
+		}
+	}
+	return opts
+}
+
+// captureClient records the rows read by the command, and buffers the output
+// The rows beyond the limit of the parallel read are also recorded, because each shard is limited separately.
+type captureClient struct {
+	bt.Client
+	out bytes.Buffer
+
+	mu   sync.Mutex
+	rows []*bt.Row
+}
+
+func (c *captureClient) OutStream() io.Writer {
+	return &c.out
+}
+
+func (c *captureClient) ErrStream() io.Writer {
+	return &c.out
+}
+
+func (c *captureClient) add(rows ...*bt.Row) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, r := range rows {
+		// the missing row of Get has no columns
+		if len(r.Columns) > 0 {
+			c.rows = append(c.rows, r)
+		}
+	}
+}
+
+func (c *captureClient) Get(ctx context.Context, table, key string, opts ...bigtable.ReadOption) (*bt.Bigtable, error) {
+	b, err := c.Client.Get(ctx, table, key, opts...)
+	if err == nil {
+		c.add(b.Rows...)
+	}
+	return b, err
+}
+
+func (c *captureClient) GetRows(ctx context.Context, table string, rr bigtable.RowRange, opts ...bigtable.ReadOption) (*bt.Bigtable, error) {
+	b, err := c.Client.GetRows(ctx, table, rr, opts...)
+	if err == nil {
+		c.add(b.Rows...)
+	}
+	return b, err
+}
+
+func (c *captureClient) ReadRows(ctx context.Context, table string, rr bigtable.RowRange, fn func(*bt.Row) bool, opts ...bigtable.ReadOption) error {
+	return c.Client.ReadRows(ctx, table, rr, func(r *bt.Row) bool {
+		c.add(r)
+		return fn(r)
+	}, opts...)
+}
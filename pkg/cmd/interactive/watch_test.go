@@ -0,0 +1,190 @@
+package interactive
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/config"
+	"github.com/takashabe/btcli/pkg/evaluator/cbt"
+)
+
+func watchColumn(qualifier, value string, sec int) *bt.Column {
+	return &bt.Column{
+		Family:    "d",
+		Qualifier: qualifier,
+		Value:     []byte(value),
+		Version:   time.Unix(int64(sec), 0),
+	}
+}
+
+func TestDiffRows(t *testing.T) {
+	prev := []*bt.Row{
+		{Key: "1", Columns: []*bt.Column{watchColumn("d:a", "a", 1), watchColumn("d:b", "b", 1), watchColumn("d:c", "c", 1)}},
+		{Key: "2", Columns: []*bt.Column{watchColumn("d:a", "a", 1)}},
+	}
+	rows := []*bt.Row{
+		// b is changed, c is rewritten with a new version, d is added
+		{Key: "1", Columns: []*bt.Column{watchColumn("d:a", "a", 1), watchColumn("d:b", "x", 1), watchColumn("d:c", "y", 2), watchColumn("d:d", "d", 1)}},
+		{Key: "3", Columns: []*bt.Column{watchColumn("d:a", "a", 1)}},
+	}
+
+	colors, removed := diffRows(prev, rows)
+	assert.Equal(t, map[cellID]string{
+		{"1", "d:b", time.Unix(1, 0).UnixNano()}: colorChanged,
+		{"1", "d:c", time.Unix(2, 0).UnixNano()}: colorChanged,
+		{"1", "d:d", time.Unix(1, 0).UnixNano()}: colorAdded,
+		{"2", "d:a", time.Unix(1, 0).UnixNano()}: colorRemoved,
+		{"3", "d:a", time.Unix(1, 0).UnixNano()}: colorAdded,
+	}, colors)
+	assert.Equal(t, map[string][]*bt.Column{"2": prev[1].Columns}, removed)
+
+	// the first run has no changes
+	colors, removed = diffRows(nil, rows)
+	assert.Empty(t, colors)
+	assert.Empty(t, removed)
+}
+
+func TestDoWatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	row := func(value string) *bt.Bigtable {
+		return &bt.Bigtable{Table: "users", Rows: []*bt.Row{{Key: "4", Columns: []*bt.Column{watchColumn("d:row", value, 1)}}}}
+	}
+	gomock.InOrder(
+		mockClient.EXPECT().Get(gomock.Any(), "users", "4", gomock.Any()).Return(row("kyouko"), nil),
+		mockClient.EXPECT().Get(gomock.Any(), "users", "4", gomock.Any()).DoAndReturn(
+			func(context.Context, string, string, ...bigtable.ReadOption) (*bt.Bigtable, error) {
+				// interrupted while the second run, not redrawn
+				cancel()
+				return row("anko"), nil
+			}),
+	)
+
+	err := doWatch(ctx, mockClient, "1ms", "lookup", "users", "4")
+	assert.NoError(t, err)
+	out := buf.String()
+	assert.Contains(t, out, clearScreen+"Every 1ms: lookup users 4\t")
+	assert.Contains(t, out, "    \"kyouko\"\n")
+	assert.NotContains(t, out, colorChanged)
+}
+
+func TestDoWatchVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// "$$" is expanded only once
+	mockClient.EXPECT().Get(gomock.Any(), "users", "$4", gomock.Any()).DoAndReturn(
+		func(context.Context, string, string, ...bigtable.ReadOption) (*bt.Bigtable, error) {
+			cancel()
+			return &bt.Bigtable{Table: "users", Rows: []*bt.Row{{Key: "$4"}}}, nil
+		})
+
+	e := &Executor{client: mockClient}
+	assert.NoError(t, e.execute(ctx, "watch 1ms lookup users $$4"))
+}
+
+func TestDoWatchHighlight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+
+	w := &watcher{st: &statement{cmd: Command{Name: "lookup", Runner: cbt.DoLookup, Options: cbt.LookupOptions, Rows: true}, args: []string{"users", "4"}}}
+	gomock.InOrder(
+		mockClient.EXPECT().Get(gomock.Any(), "users", "4", gomock.Any()).Return(
+			&bt.Bigtable{Table: "users", Rows: []*bt.Row{{Key: "4", Columns: []*bt.Column{watchColumn("d:row", "kyouko", 1)}}}}, nil),
+		mockClient.EXPECT().Get(gomock.Any(), "users", "4", gomock.Any()).Return(
+			&bt.Bigtable{Table: "users", Rows: []*bt.Row{{Key: "4", Columns: []*bt.Column{watchColumn("d:row", "anko", 2)}}}}, nil),
+		mockClient.EXPECT().Get(gomock.Any(), "users", "4", gomock.Any()).Return(
+			&bt.Bigtable{Table: "users", Rows: []*bt.Row{{Key: "4"}}}, nil),
+	)
+
+	assert.NoError(t, w.run(context.Background(), mockClient))
+	buf.Reset()
+	assert.NoError(t, w.run(context.Background(), mockClient))
+	assert.Contains(t, buf.String(), colorChanged+"  d:row")
+	assert.Contains(t, buf.String(), "    \"anko\"\n\x1b[0m")
+
+	// the row is deleted
+	buf.Reset()
+	assert.NoError(t, w.run(context.Background(), mockClient))
+	assert.Contains(t, buf.String(), colorRemoved+"  d:row")
+	assert.Contains(t, buf.String(), "not found error: Row not found: 4\n")
+}
+
+func TestDoWatchQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func() { library = config.NewLibrary("") }()
+	library.Queries["user"] = "lookup users"
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockClient.EXPECT().Get(gomock.Any(), "users", "4", gomock.Any()).DoAndReturn(
+		func(context.Context, string, string, ...bigtable.ReadOption) (*bt.Bigtable, error) {
+			cancel()
+			return &bt.Bigtable{Table: "users", Rows: []*bt.Row{{Key: "4"}}}, nil
+		})
+
+	assert.NoError(t, doWatch(ctx, mockClient, "1ms", "run", "user", "4"))
+}
+
+func TestDoWatchInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func() { library = config.NewLibrary("") }()
+	library.Queries["sync-users"] = "sync users users_v2 yes=true"
+
+	mockClient := bt.NewMockClient(ctrl)
+	cases := [][]string{
+		{},
+		{"5s"},
+		{"0s", "ls"},
+		{"five", "ls"},
+		{"5s", "unknown"},
+		{"5s", "tail", "users"},
+		{"5s", "watch", "5s", "ls"},
+		{"5s", "sync", "users", "users_v2"},
+		{"5s", "copy", "users", "users_v2"},
+		{"5s", "export", "users", "users.jsonl"},
+		{"5s", "run", "sync-users"},
+		{"5s", "next"},
+		{"5s", "use", "users"},
+		{"5s", "let", "x=1"},
+		{"5s", "alias", "rr=read"},
+		{"5s", "save", "query", "q", "ls"},
+		{"5s", "profile", "prod", "instance=prod"},
+		{"5s", "set", "pager", "off"},
+		{"5s", `\timing`},
+		{"5s", "exit"},
+	}
+	for _, c := range cases {
+		err := doWatch(context.Background(), mockClient, c...)
+		assert.Equal(t, cbt.KindUsage, cbt.KindOf(err), "args: %v", c)
+	}
+}
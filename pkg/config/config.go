@@ -27,6 +27,17 @@ type Config struct {
 	Creds       string
 	TokenSource oauth2.TokenSource
 
+	// Library is the command aliases and the saved queries
+	Library *Library
+
+	// HistorySize is the max number of the history entries
+	HistorySize int
+	// HistoryPerInstance represents the history file is separated by the project and the instance
+	HistoryPerInstance bool
+
+	// Stats represents the time and the statistics of the requests are printed in the one-shot mode
+	Stats bool
+
 	ErrStream io.Writer
 }
 
@@ -35,6 +46,9 @@ func (c *Config) registerFlags() {
 	flag.StringVar(&c.Project, "project", c.Project, "project ID, if unset uses gcloud configured project")
 	flag.StringVar(&c.Instance, "instance", c.Instance, "Cloud Bigtable instance")
 	flag.StringVar(&c.Creds, "creds", c.Creds, "if set, use application credentials in this file")
+	flag.IntVar(&c.HistorySize, "history-size", 1000, "max number of the history entries")
+	flag.BoolVar(&c.HistoryPerInstance, "history-per-instance", false, "if set, separate the history file by the project and the instance")
+	flag.BoolVar(&c.Stats, "stats", false, "if set, print the time and the statistics of the requests to stderr after the command")
 }
 
 // NewConfig returns initialized config.
@@ -79,9 +93,27 @@ func (c *Config) Load() error {
 		return err
 	}
 
+	c.Library = c.loadLibrary()
+
 	return s.Err()
 }
 
+// loadLibrary returns the library, or the empty library with the warning when the library is not available.
+func (c *Config) loadLibrary() *Library {
+	path, err := LibraryPath()
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "Failed to find the library: %v\n", err)
+		return NewLibrary("")
+	}
+	l, err := LoadLibrary(path)
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "Failed to load the library: %v\n", err)
+		// not saved, not to overwrite the library to be fixed by the user
+		return NewLibrary("")
+	}
+	return l
+}
+
 type gcloudCredential struct {
 	AccessToken string    `json:"access_token"`
 	Expiry      time.Time `json:"token_expiry"`
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Library is the command aliases, the saved queries and the connection profiles.
+// The file can be shared with the team by BTCLI_LIBRARY environment.
+type Library struct {
+	Aliases  map[string]string  `json:"aliases"`
+	Queries  map[string]string  `json:"queries"`
+	Profiles map[string]Profile `json:"profiles"`
+
+	path string
+}
+
+// Profile is the named project and instance, to refer the table of the other instance as "<profile>:<table>"
+type Profile struct {
+	Project  string `json:"project"`
+	Instance string `json:"instance"`
+}
+
+// NewLibrary returns the empty library stored in the path.
+func NewLibrary(path string) *Library {
+	return &Library{
+		Aliases:  map[string]string{},
+		Queries:  map[string]string{},
+		Profiles: map[string]Profile{},
+		path:     path,
+	}
+}
+
+// LibraryPath returns the path of the library file.
+// Default is "btcli/library.json" under the user's config directory.
+func LibraryPath() (string, error) {
+	if p := os.Getenv("BTCLI_LIBRARY"); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "btcli", "library.json"), nil
+}
+
+// LoadLibrary returns the library read from the path, or the empty library when the file not exists.
+func LoadLibrary(path string) (*Library, error) {
+	l := NewLibrary(path)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, fmt.Errorf("Reading %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("Parsing %s: %v", path, err)
+	}
+	if l.Aliases == nil {
+		l.Aliases = map[string]string{}
+	}
+	if l.Queries == nil {
+		l.Queries = map[string]string{}
+	}
+	if l.Profiles == nil {
+		l.Profiles = map[string]Profile{}
+	}
+	return l, nil
+}
+
+// Save writes the library to the file.
+func (l *Library) Save() error {
+	if l.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(l.path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibrary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "btcli", "library.json")
+
+	// not exists
+	l, err := LoadLibrary(path)
+	assert.NoError(t, err)
+	assert.Empty(t, l.Aliases)
+	assert.Empty(t, l.Queries)
+	assert.Empty(t, l.Profiles)
+
+	l.Aliases["rr"] = "read users decode=int"
+	l.Queries["hot-users"] = "read users prefix=2## count=50"
+	l.Profiles["prod"] = Profile{Project: "my-project", Instance: "prod"}
+	assert.NoError(t, l.Save())
+
+	actual, err := LoadLibrary(path)
+	assert.NoError(t, err)
+	assert.Equal(t, l.Aliases, actual.Aliases)
+	assert.Equal(t, l.Queries, actual.Queries)
+	assert.Equal(t, l.Profiles, actual.Profiles)
+
+	// broken file
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	_, err = LoadLibrary(path)
+	assert.Error(t, err)
+}
+
+func TestLibraryPath(t *testing.T) {
+	defer os.Setenv("BTCLI_LIBRARY", os.Getenv("BTCLI_LIBRARY"))
+
+	os.Setenv("BTCLI_LIBRARY", "/shared/library.json")
+	p, err := LibraryPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/shared/library.json", p)
+}
+
+func TestConfigLoadLibrary(t *testing.T) {
+	defer os.Setenv("BTCLI_LIBRARY", os.Getenv("BTCLI_LIBRARY"))
+
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "library.json")
+	os.Setenv("BTCLI_LIBRARY", path)
+
+	// the broken library is warned, and the empty library is used
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	var buf bytes.Buffer
+	c := NewConfig(&buf)
+	l := c.loadLibrary()
+	assert.Empty(t, l.Aliases)
+	assert.Contains(t, buf.String(), "Failed to load the library: Parsing "+path)
+
+	// the broken library is not overwritten
+	l.Aliases["rr"] = "read"
+	assert.NoError(t, l.Save())
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "{", string(data))
+}
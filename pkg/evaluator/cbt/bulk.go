@@ -0,0 +1,36 @@
+package cbt
+
+import (
+	"context"
+
+	"cloud.google.com/go/bigtable"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+// bulkBatchSize is the number of the rows applied by a bulk request
+const bulkBatchSize = 100
+
+// applyBulk applies the mutations by the bulk requests of bulkBatchSize rows.
+// The rows failed to apply are passed to onError, and returns the number of the applied rows.
+// Stops at the failure of the request itself.
+func applyBulk(ctx context.Context, client bt.Client, table string, keys []string, muts []*bigtable.Mutation, onError func(key string, err error)) (int, error) {
+	applied := 0
+	for i := 0; i < len(keys); i += bulkBatchSize {
+		end := i + bulkBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		errs, err := client.ApplyBulk(ctx, table, keys[i:end], muts[i:end])
+		if err != nil {
+			return applied, err
+		}
+		for j, key := range keys[i:end] {
+			if errs != nil && errs[j] != nil {
+				onError(key, errs[j])
+				continue
+			}
+			applied++
+		}
+	}
+	return applied, nil
+}
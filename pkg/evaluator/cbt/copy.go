@@ -0,0 +1,185 @@
+package cbt
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"cloud.google.com/go/bigtable"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+// DoCopy copies the rows of the source table to the destination table, keeping the timestamps of the cells by default.
+// The rows are read by the stream and written by the bulk requests, the rows failed to write are printed and skipped.
+func DoCopy(ctx context.Context, client bt.Client, args ...string) error {
+	positional, opts := CopyOptions.Split(args)
+	if len(positional) != 2 {
+		return UsageError("Invalid args: copy <src-table> <dst-table> [args ...]")
+	}
+	parsed, err := CopyOptions.Parse(opts)
+	if err != nil {
+		return err
+	}
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		return UsageError(`"start"/"end" may not be mixed with "prefix"`)
+	}
+
+	src, srcTable, err := resolveTable(client, positional[0])
+	if err != nil {
+		return err
+	}
+	dst, dstTable, err := resolveTable(client, positional[1])
+	if err != nil {
+		return err
+	}
+	renames, _ := parseRenames(parsed["rename-family"])
+	transform, _ := parseKeyTransform(parsed["key-transform"])
+	c := &copier{
+		renames:        renames,
+		transform:      transform,
+		keepTimestamps: parsed.Bool("keep-timestamps"),
+	}
+
+	var copied, failed int64
+	stopProgress := showProgress(client.ErrStream(), "copied", &copied)
+	err = c.copy(ctx, src, srcTable, dst, dstTable, parsed, &copied, func(key string, err error) {
+		fmt.Fprintf(client.ErrStream(), "Failed to copy %s: %v\n", key, err)
+		failed++
+	})
+	stopProgress()
+
+	fmt.Fprintf(client.OutStream(), "%d rows copied to %s\n", atomic.LoadInt64(&copied), positional[1])
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return WrapError(fmt.Errorf("%d rows failed to copy", failed))
+	}
+	return nil
+}
+
+// copier converts the rows to the mutations of the destination table
+type copier struct {
+	// renames is the families of the destination by the source family
+	renames map[string]string
+	// transform rewrites the row key, nil keeps the key
+	transform      func(string) string
+	keepTimestamps bool
+}
+
+// copy reads the rows by the stream and writes them by the bulk requests of bulkBatchSize rows, and adds the number of the written rows to copied.
+func (c *copier) copy(ctx context.Context, src bt.Client, srcTable string, dst bt.Client, dstTable string, parsed Values, copied *int64, onError func(key string, err error)) error {
+	rr, err := rowRange(parsed)
+	if err != nil {
+		return UsageError("Invalid range: %v", err)
+	}
+	ro, err := readOption(parsed)
+	if err != nil {
+		return UsageError("Invalid options: %v", err)
+	}
+
+	var (
+		keys     []string
+		muts     []*bigtable.Mutation
+		applyErr error
+	)
+	flush := func() bool {
+		n, err := applyBulk(ctx, dst, dstTable, keys, muts, onError)
+		atomic.AddInt64(copied, int64(n))
+		keys, muts = keys[:0], muts[:0]
+		applyErr = err
+		return err == nil
+	}
+	err = src.ReadRows(ctx, srcTable, rr, func(r *bt.Row) bool {
+		key, m := c.mutation(r)
+		keys = append(keys, key)
+		muts = append(muts, m)
+		if len(keys) < bulkBatchSize {
+			return true
+		}
+		return flush()
+	}, ro...)
+	if err == nil && applyErr == nil && len(keys) > 0 {
+		flush()
+	}
+	if applyErr != nil {
+		return WrapError(applyErr)
+	}
+	if err != nil {
+		return WrapError(err)
+	}
+	return nil
+}
+
+// mutation returns the row key and the mutation to write the row.
+func (c *copier) mutation(r *bt.Row) (string, *bigtable.Mutation) {
+	m := bigtable.NewMutation()
+	for _, col := range r.Columns {
+		family, column := splitColumn(col)
+		if f, ok := c.renames[family]; ok {
+			family = f
+		}
+		ts := bigtable.ServerTime
+		if c.keepTimestamps {
+			ts = bigtable.Time(col.Version)
+		}
+		m.Set(family, column, ts, col.Value)
+	}
+
+	key := r.Key
+	if c.transform != nil {
+		key = c.transform(key)
+	}
+	return key, m
+}
+
+// parseRenames parses "<from>:<to>[,<from>:<to>...]" to the map of the families.
+func parseRenames(v string) (map[string]string, error) {
+	renames := map[string]string{}
+	if v == "" {
+		return renames, nil
+	}
+	for _, pair := range strings.Split(v, ",") {
+		fams := strings.Split(pair, ":")
+		if len(fams) != 2 || fams[0] == "" || fams[1] == "" {
+			return nil, fmt.Errorf("must be <from>:<to>[,<from>:<to>...]")
+		}
+		renames[fams[0]] = fams[1]
+	}
+	return renames, nil
+}
+
+func validRenames(v string) error {
+	_, err := parseRenames(v)
+	return err
+}
+
+// parseKeyTransform parses "s/<regex>/<replacement>/" like sed, and returns the func to rewrite the key.
+// The replacement may refer the submatch as $1. Any character may be the delimiter instead of "/".
+func parseKeyTransform(v string) (func(string) string, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if len(v) < 2 || v[0] != 's' {
+		return nil, fmt.Errorf("must be s/<regex>/<replacement>/")
+	}
+	parts := strings.Split(v[2:], v[1:2])
+	if len(parts) != 3 || parts[0] == "" || parts[2] != "" {
+		return nil, fmt.Errorf("must be s/<regex>/<replacement>/")
+	}
+	re, err := regexp.Compile(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	repl := parts[1]
+	return func(key string) string {
+		return re.ReplaceAllString(key, repl)
+	}, nil
+}
+
+func validKeyTransform(v string) error {
+	_, err := parseKeyTransform(v)
+	return err
+}
@@ -0,0 +1,122 @@
+package cbt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+func TestDoCopy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var out, errOut bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&out).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&errOut).AnyTimes()
+	rows := []*bt.Row{
+		{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}},
+		{Key: "10", Columns: []*bt.Column{diffColumn("d:row", "homura", 1), diffColumn("d:age", "14", 2)}},
+		{Key: "12", Columns: []*bt.Column{diffColumn("d:row", "sayaka", 1)}},
+	}
+	for i := 0; i < bulkBatchSize-2; i++ {
+		rows = append(rows, &bt.Row{Key: fmt.Sprintf("2%03d", i), Columns: []*bt.Column{diffColumn("d:row", "kyouko", 1)}})
+	}
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users", bigtable.RowRange{}, gomock.Any()).DoAndReturn(readRowsFrom(rows...))
+
+	ts := bigtable.Time(diffColumn("d:row", "", 1).Version)
+	m1 := bigtable.NewMutation()
+	m1.Set("e", "row", ts, []byte("madoka"))
+	m10 := bigtable.NewMutation()
+	m10.Set("e", "row", ts, []byte("homura"))
+	m10.Set("e", "age", bigtable.Time(diffColumn("d:age", "", 2).Version), []byte("14"))
+	m12 := bigtable.NewMutation()
+	m12.Set("e", "row", ts, []byte("sayaka"))
+	// written by the batches of bulkBatchSize rows while reading
+	gomock.InOrder(
+		mockClient.EXPECT().ApplyBulk(gomock.Any(), "users_v2", gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, keys []string, muts []*bigtable.Mutation) ([]error, error) {
+				assert.Len(t, keys, bulkBatchSize)
+				assert.Equal(t, []string{"user#1", "user#10", "user#12"}, keys[:3])
+				assert.Equal(t, []*bigtable.Mutation{m1, m10, m12}, muts[:3])
+				errs := make([]error, len(keys))
+				errs[2] = errors.New("unavailable")
+				return errs, nil
+			}),
+		mockClient.EXPECT().ApplyBulk(gomock.Any(), "users_v2", []string{"user#2097"}, gomock.Any()).Return(nil, nil),
+	)
+
+	err := DoCopy(context.Background(), mockClient, "users", "users_v2", "rename-family=d:e", "key-transform=s/^/user#/")
+	assert.Equal(t, KindServer, KindOf(err))
+	assert.Equal(t, fmt.Sprintf("%d rows copied to users_v2\n", bulkBatchSize), out.String())
+	assert.Equal(t, "Failed to copy user#12: unavailable\n", errOut.String())
+}
+
+func TestDoCopyCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var out bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&out).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&out).AnyTimes()
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users", bigtable.PrefixRange("1"), gomock.Any(), gomock.Any()).DoAndReturn(readRowsFrom(
+		&bt.Row{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}},
+	))
+
+	m1 := bigtable.NewMutation()
+	m1.Set("d", "row", bigtable.ServerTime, []byte("madoka"))
+	mockClient.EXPECT().ApplyBulk(gomock.Any(), "users_v2", []string{"1"}, []*bigtable.Mutation{m1}).Return(nil, nil)
+
+	err := DoCopy(context.Background(), mockClient, "users", "users_v2", "prefix=1", "count=1", "keep-timestamps=false")
+	assert.NoError(t, err)
+	assert.Equal(t, "1 rows copied to users_v2\n", out.String())
+}
+
+func TestDoCopyInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	for _, args := range [][]string{
+		{"users"},
+		{"users", "1", "users_v2"},
+		{"users", "users_v2", "prefix=1", "start=1"},
+		{"users", "users_v2", "count=-1"},
+		{"users", "users_v2", "rename-family=d"},
+		{"users", "users_v2", "key-transform=^1"},
+		{"users", "users_v2", "key-transform=s/(/x/"},
+	} {
+		err := DoCopy(context.Background(), mockClient, args...)
+		assert.Equal(t, KindUsage, KindOf(err), "args: %v", args)
+	}
+}
+
+func TestParseKeyTransform(t *testing.T) {
+	cases := []struct {
+		expr   string
+		key    string
+		expect string
+	}{
+		{"s/^1/u1/", "10", "u10"},
+		{"s/#(.*)$/_$1/", "user#10", "user_10"},
+		{"s|/|#|", "a/b/c", "a#b#c"},
+	}
+	for _, c := range cases {
+		fn, err := parseKeyTransform(c.expr)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expect, fn(c.key), "expr: %s", c.expr)
+	}
+
+	for _, expr := range []string{"s", "s/a/b", "s/a/b/c", "x/a/b/"} {
+		_, err := parseKeyTransform(expr)
+		assert.Error(t, err, "expr: %s", expr)
+	}
+}
@@ -0,0 +1,101 @@
+package cbt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// lastCursor holds the last read query to continue it by the next command.
+var lastCursor *readCursor
+
+// moreInput is read when waiting for the next page.
+var moreInput io.Reader = os.Stdin
+
+// readCursor represents a position of the read query
+type readCursor struct {
+	table   string
+	parsed  Values
+	lastKey string
+	done    bool
+}
+
+// nextArgs returns the parsed args to read rows just after the last key.
+func (c *readCursor) nextArgs() Values {
+	next := copyArgs(c.parsed)
+	if prefix := next["prefix"]; prefix != "" {
+		delete(next, "prefix")
+		next["end"] = prefixSuccessor(prefix)
+	}
+	// the smallest key greater than lastKey
+	next["start"] = c.lastKey + "\x00"
+	return next
+}
+
+func copyArgs(parsedArgs Values) Values {
+	ret := make(Values, len(parsedArgs))
+	for k, v := range parsedArgs {
+		ret[k] = v
+	}
+	return ret
+}
+
+// prefixSuccessor returns the lexically smallest string greater than the prefix.
+// Empty string means infinity.
+func prefixSuccessor(prefix string) string {
+	n := len(prefix)
+	for n--; n >= 0 && prefix[n] == '\xff'; n-- {
+	}
+	if n < 0 {
+		return ""
+	}
+	return prefix[:n] + string([]byte{prefix[n] + 1})
+}
+
+// waitMore waits for a user input like a more command, and returns whether to continue.
+func waitMore(w io.Writer) bool {
+	return WaitMore(moreInput, w)
+}
+
+// IsTerminal reports whether the output is written to the terminal, so the user can answer the prompt.
+// Replaced in the tests.
+var IsTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// WaitMore prints the more prompt to w and waits for a line from r, and returns whether to continue.
+func WaitMore(r io.Reader, w io.Writer) bool {
+	fmt.Fprint(w, "-- More -- (Enter: next page, q: quit) ")
+	line, err := readLine(r)
+	if err != nil {
+		fmt.Fprintln(w)
+		return false
+	}
+	return strings.TrimSpace(line) != "q"
+}
+
+// readLine reads a line without buffering, so as not to consume the following inputs.
+func readLine(r io.Reader) (string, error) {
+	var (
+		line []byte
+		b    = make([]byte, 1)
+	)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			return string(line), err
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package cbt
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+func TestNextArgs(t *testing.T) {
+	cases := []struct {
+		cursor *readCursor
+		expect Values
+	}{
+		{
+			&readCursor{
+				parsed:  Values{"prefix": "a", "count": "1"},
+				lastKey: "a1",
+			},
+			Values{"start": "a1\x00", "end": "b", "count": "1"},
+		},
+		{
+			&readCursor{
+				parsed:  Values{"start": "a", "end": "c"},
+				lastKey: "b",
+			},
+			Values{"start": "b\x00", "end": "c"},
+		},
+		{
+			&readCursor{
+				parsed:  Values{"prefix": "\xff"},
+				lastKey: "\xff1",
+			},
+			Values{"start": "\xff1\x00", "end": ""},
+		},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expect, c.cursor.nextArgs())
+	}
+}
+
+func keysOnlyRows(keys ...string) *bt.Bigtable {
+	rows := make([]*bt.Row, 0, len(keys))
+	for _, k := range keys {
+		rows = append(rows, &bt.Row{Key: k})
+	}
+	return &bt.Bigtable{Table: "table", Rows: rows}
+}
+
+func TestDoNext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+
+	lastCursor = nil
+	err := DoNext(context.Background(), mockClient)
+	assert.EqualError(t, err, "No previous read query")
+	assert.Equal(t, KindUsage, KindOf(err))
+
+	gomock.InOrder(
+		mockClient.EXPECT().GetRows(
+			gomock.Any(), "table", bigtable.PrefixRange("a"), gomock.Any(), bigtable.LimitRows(2),
+		).Return(keysOnlyRows("a1", "a2"), nil),
+		mockClient.EXPECT().GetRows(
+			gomock.Any(), "table", bigtable.NewRange("a2\x00", "b"), gomock.Any(), bigtable.LimitRows(2),
+		).Return(keysOnlyRows("a3"), nil),
+	)
+
+	buf.Reset()
+	DoRead(context.Background(), mockClient, "table", "prefix=a", "count=2", "keys-only=true")
+	assert.Equal(t, "a1\na2\n", buf.String())
+
+	buf.Reset()
+	DoNext(context.Background(), mockClient)
+	assert.Equal(t, "a3\n", buf.String())
+
+	buf.Reset()
+	err = DoNext(context.Background(), mockClient)
+	assert.EqualError(t, err, "No more rows")
+}
+
+func TestDoReadWithPageSize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cases := []struct {
+		input   []string
+		more    string
+		expect  string
+		prepare func(*bt.MockClient)
+	}{
+		{
+			[]string{"table", "page-size=2", "keys-only=true"},
+			"\n",
+			"a1\na2\n-- More -- (Enter: next page, q: quit) a3\n",
+			func(mock *bt.MockClient) {
+				gomock.InOrder(
+					mock.EXPECT().GetRows(
+						gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), bigtable.LimitRows(2),
+					).Return(keysOnlyRows("a1", "a2"), nil),
+					mock.EXPECT().GetRows(
+						gomock.Any(), "table", bigtable.InfiniteRange("a2\x00"), gomock.Any(), bigtable.LimitRows(2),
+					).Return(keysOnlyRows("a3"), nil),
+				)
+			},
+		},
+		{
+			[]string{"table", "page-size=2", "keys-only=true"},
+			"q\n",
+			"a1\na2\n-- More -- (Enter: next page, q: quit) ",
+			func(mock *bt.MockClient) {
+				mock.EXPECT().GetRows(
+					gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), bigtable.LimitRows(2),
+				).Return(keysOnlyRows("a1", "a2"), nil)
+			},
+		},
+		{
+			// count limits the total rows of the pages
+			[]string{"table", "page-size=2", "count=3", "keys-only=true"},
+			"\n",
+			"a1\na2\n-- More -- (Enter: next page, q: quit) a3\n",
+			func(mock *bt.MockClient) {
+				gomock.InOrder(
+					mock.EXPECT().GetRows(
+						gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), bigtable.LimitRows(2),
+					).Return(keysOnlyRows("a1", "a2"), nil),
+					mock.EXPECT().GetRows(
+						gomock.Any(), "table", bigtable.InfiniteRange("a2\x00"), gomock.Any(), bigtable.LimitRows(1),
+					).Return(keysOnlyRows("a3"), nil),
+				)
+			},
+		},
+	}
+	defer func(f func(io.Writer) bool) { IsTerminal = f }(IsTerminal)
+	IsTerminal = func(io.Writer) bool { return true }
+	for _, c := range cases {
+		mockClient := bt.NewMockClient(ctrl)
+		c.prepare(mockClient)
+		moreInput = strings.NewReader(c.more)
+
+		var buf bytes.Buffer
+		mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+		mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+
+		DoRead(context.Background(), mockClient, c.input...)
+		assert.Equal(t, c.expect, buf.String())
+	}
+}
+
+func TestDoReadWithPageSizeRedirected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	gomock.InOrder(
+		mockClient.EXPECT().GetRows(
+			gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), bigtable.LimitRows(2),
+		).Return(keysOnlyRows("a1", "a2"), nil),
+		mockClient.EXPECT().GetRows(
+			gomock.Any(), "table", bigtable.InfiniteRange("a2\x00"), gomock.Any(), bigtable.LimitRows(2),
+		).Return(keysOnlyRows("a3"), nil),
+	)
+	// not waiting for the input
+	moreInput = strings.NewReader("q\n")
+
+	var out, errOut bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&out).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&errOut).AnyTimes()
+
+	err := DoRead(context.Background(), mockClient, "table", "page-size=2", "keys-only=true")
+	assert.NoError(t, err)
+	assert.Equal(t, "a1\na2\na3\n", out.String())
+	assert.Empty(t, errOut.String())
+}
@@ -0,0 +1,312 @@
+package cbt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+// DoDiff compares the cells of two rows, or the rows in the same range of two tables.
+// The second table or row is regarded as the new one, so its extra cells are printed as added.
+// The table of the other instance is referred as "<profile>:<table>".
+func DoDiff(ctx context.Context, client bt.Client, args ...string) error {
+	positional, opts := DiffOptions.Split(args)
+	parsed, err := DiffOptions.Parse(opts)
+	if err != nil {
+		return err
+	}
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		return UsageError(`"start"/"end" may not be mixed with "prefix"`)
+	}
+
+	d := &differ{
+		out:         client.OutStream(),
+		summaryOnly: parsed.Bool("summary-only"),
+	}
+	switch len(positional) {
+	case 2:
+		a, tableA, err := resolveTable(client, positional[0])
+		if err != nil {
+			return err
+		}
+		b, tableB, err := resolveTable(client, positional[1])
+		if err != nil {
+			return err
+		}
+		d.labelA, d.labelB = positional[0], positional[1]
+		return d.diffTables(ctx, a, tableA, b, tableB, parsed)
+	case 4:
+		if parsed["start"] != "" || parsed["end"] != "" || parsed["prefix"] != "" || parsed["regex"] != "" {
+			return UsageError("The range options may not be used to compare the rows")
+		}
+		a, tableA, err := resolveTable(client, positional[0])
+		if err != nil {
+			return err
+		}
+		b, tableB, err := resolveTable(client, positional[2])
+		if err != nil {
+			return err
+		}
+		d.labelA = positional[0] + " " + positional[1]
+		d.labelB = positional[2] + " " + positional[3]
+		return d.diffRows(ctx, a, tableA, positional[1], b, tableB, positional[3], parsed)
+	default:
+		return UsageError("Invalid args: diff <table> <row> <table> <row> | diff <table> <table> [args ...]")
+	}
+}
+
+// differ prints the different cells and the summary
+type differ struct {
+	out            io.Writer
+	labelA, labelB string
+	summaryOnly    bool
+
+	// onDiff is called with the rows that have the different cells, the missing row has no columns
+	onDiff func(a, b *bt.Row, cells []cellDiff)
+
+	headerPrinted bool
+	summary       diffSummary
+}
+
+// diffSummary is the number of the different rows and cells
+type diffSummary struct {
+	rows        int
+	rowsDiffer  int
+	onlyA       int
+	onlyB       int
+	cellAdded   int
+	cellRemoved int
+	cellChanged int
+}
+
+func (d *differ) diffRows(ctx context.Context, clientA bt.Client, tableA, keyA string, clientB bt.Client, tableB, keyB string, parsed Values) error {
+	ro, err := readOption(parsed)
+	if err != nil {
+		return UsageError("Invalid options: %v", err)
+	}
+	a, err := clientA.Get(ctx, tableA, keyA, ro...)
+	if err != nil {
+		return WrapError(err)
+	}
+	b, err := clientB.Get(ctx, tableB, keyB, ro...)
+	if err != nil {
+		return WrapError(err)
+	}
+
+	label := keyA
+	if keyA != keyB {
+		label = keyA + " " + keyB
+	}
+	d.compare(label, a.Rows[0], b.Rows[0])
+	d.printSummary()
+	return nil
+}
+
+func (d *differ) diffTables(ctx context.Context, clientA bt.Client, tableA string, clientB bt.Client, tableB string, parsed Values) error {
+	rr, err := rowRange(parsed)
+	if err != nil {
+		return UsageError("Invalid range: %v", err)
+	}
+	ro, err := readOption(parsed)
+	if err != nil {
+		return UsageError("Invalid options: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sa := newRowStream(ctx, clientA, tableA, rr, ro...)
+	sb := newRowStream(ctx, clientB, tableB, rr, ro...)
+
+	ra, err := sa.next()
+	if err != nil {
+		return WrapError(err)
+	}
+	rb, err := sb.next()
+	if err != nil {
+		return WrapError(err)
+	}
+	for ra != nil || rb != nil {
+		nextA, nextB := true, true
+		switch {
+		case rb == nil || (ra != nil && ra.Key < rb.Key):
+			d.compare(ra.Key, ra, &bt.Row{Key: ra.Key})
+			nextB = false
+		case ra == nil || rb.Key < ra.Key:
+			d.compare(rb.Key, &bt.Row{Key: rb.Key}, rb)
+			nextA = false
+		default:
+			d.compare(ra.Key, ra, rb)
+		}
+		if nextA {
+			if ra, err = sa.next(); err != nil {
+				return WrapError(err)
+			}
+		}
+		if nextB {
+			if rb, err = sb.next(); err != nil {
+				return WrapError(err)
+			}
+		}
+	}
+	d.printSummary()
+	return nil
+}
+
+// compare prints the different cells of the rows and counts them.
+// The row without the columns is regarded as missing.
+func (d *differ) compare(label string, a, b *bt.Row) {
+	d.summary.rows++
+	cells := diffCells(a, b)
+	if len(cells) == 0 {
+		return
+	}
+
+	d.summary.rowsDiffer++
+	if d.onDiff != nil {
+		d.onDiff(a, b, cells)
+	}
+	switch {
+	case len(b.Columns) == 0:
+		d.summary.onlyA++
+	case len(a.Columns) == 0:
+		d.summary.onlyB++
+	}
+	for _, c := range cells {
+		switch c.op {
+		case diffAdded:
+			d.summary.cellAdded++
+		case diffRemoved:
+			d.summary.cellRemoved++
+		case diffChanged:
+			d.summary.cellChanged++
+		}
+	}
+	if d.summaryOnly {
+		return
+	}
+
+	if !d.headerPrinted {
+		fmt.Fprintf(d.out, "--- %s\n+++ %s\n", d.labelA, d.labelB)
+		d.headerPrinted = true
+	}
+	fmt.Fprintf(d.out, "@@ %s\n", label)
+	for _, c := range cells {
+		c.print(d.out)
+	}
+}
+
+func (d *differ) printSummary() {
+	s := d.summary
+	fmt.Fprintf(d.out, "%d rows compared, %d rows differ (%d only in %s, %d only in %s)\n",
+		s.rows, s.rowsDiffer, s.onlyA, d.labelA, s.onlyB, d.labelB)
+	fmt.Fprintf(d.out, "%d cells added, %d removed, %d changed\n", s.cellAdded, s.cellRemoved, s.cellChanged)
+}
+
+// diff operations of the cell
+const (
+	diffAdded   = "+"
+	diffRemoved = "-"
+	diffChanged = "~"
+)
+
+// cellDiff is the difference of the cell identified by the family, the qualifier and the version
+type cellDiff struct {
+	op       string
+	old, new *bt.Column
+}
+
+func (c cellDiff) print(w io.Writer) {
+	col := c.column()
+	fmt.Fprintf(w, "%s %-40s @ %s", c.op, col.Qualifier, col.Version.Format("2006/01/02-15:04:05.000000"))
+	if c.op == diffChanged {
+		fmt.Fprintf(w, " %q -> %q\n", c.old.Value, c.new.Value)
+		return
+	}
+	fmt.Fprintf(w, " %q\n", col.Value)
+}
+
+func (c cellDiff) column() *bt.Column {
+	if c.old != nil {
+		return c.old
+	}
+	return c.new
+}
+
+type cellKey struct {
+	qualifier string
+	version   time.Time
+}
+
+// diffCells returns the different cells of the rows, sorted by the qualifier and the newer version first.
+func diffCells(a, b *bt.Row) []cellDiff {
+	olds := map[cellKey]*bt.Column{}
+	for _, c := range a.Columns {
+		olds[cellKey{c.Qualifier, c.Version}] = c
+	}
+	news := map[cellKey]*bt.Column{}
+	for _, c := range b.Columns {
+		news[cellKey{c.Qualifier, c.Version}] = c
+	}
+
+	var diffs []cellDiff
+	for k, o := range olds {
+		n, ok := news[k]
+		switch {
+		case !ok:
+			diffs = append(diffs, cellDiff{op: diffRemoved, old: o})
+		case !bytes.Equal(o.Value, n.Value):
+			diffs = append(diffs, cellDiff{op: diffChanged, old: o, new: n})
+		}
+	}
+	for k, n := range news {
+		if _, ok := olds[k]; !ok {
+			diffs = append(diffs, cellDiff{op: diffAdded, new: n})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		ci, cj := diffs[i].column(), diffs[j].column()
+		if ci.Qualifier != cj.Qualifier {
+			return ci.Qualifier < cj.Qualifier
+		}
+		return ci.Version.After(cj.Version)
+	})
+	return diffs
+}
+
+// rowStream reads the rows in the key order in the background
+type rowStream struct {
+	rows chan *bt.Row
+	err  error
+}
+
+func newRowStream(ctx context.Context, client bt.Client, table string, rr bigtable.RowRange, opts ...bigtable.ReadOption) *rowStream {
+	s := &rowStream{rows: make(chan *bt.Row, 100)}
+	go func() {
+		defer close(s.rows)
+		s.err = client.ReadRows(ctx, table, rr, func(r *bt.Row) bool {
+			select {
+			case s.rows <- r:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}, opts...)
+	}()
+	return s
+}
+
+// next returns the next row, or nil after the last row.
+func (s *rowStream) next() (*bt.Row, error) {
+	r, ok := <-s.rows
+	if !ok {
+		return nil, s.err
+	}
+	return r, nil
+}
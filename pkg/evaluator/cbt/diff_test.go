@@ -0,0 +1,159 @@
+package cbt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+func diffColumn(qualifier, value string, sec int64) *bt.Column {
+	return &bt.Column{Family: "d", Qualifier: qualifier, Value: []byte(value), Version: time.Unix(sec, 0).UTC()}
+}
+
+// readRowsFrom returns the ReadRows stub streaming the rows.
+func readRowsFrom(rows ...*bt.Row) func(context.Context, string, bigtable.RowRange, func(*bt.Row) bool, ...bigtable.ReadOption) error {
+	return func(_ context.Context, _ string, _ bigtable.RowRange, fn func(*bt.Row) bool, _ ...bigtable.ReadOption) error {
+		for _, r := range rows {
+			if !fn(r) {
+				break
+			}
+		}
+		return nil
+	}
+}
+
+func TestDiffCells(t *testing.T) {
+	a := &bt.Row{Key: "1", Columns: []*bt.Column{diffColumn("d:a", "a", 1), diffColumn("d:b", "b", 1), diffColumn("d:b", "b0", 0)}}
+	b := &bt.Row{Key: "1", Columns: []*bt.Column{diffColumn("d:a", "x", 1), diffColumn("d:b", "b", 1), diffColumn("d:c", "c", 1)}}
+
+	assert.Equal(t, []cellDiff{
+		{op: diffChanged, old: a.Columns[0], new: b.Columns[0]},
+		{op: diffRemoved, old: a.Columns[2]},
+		{op: diffAdded, new: b.Columns[2]},
+	}, diffCells(a, b))
+	assert.Empty(t, diffCells(a, a))
+}
+
+func TestDoDiffTables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users", bigtable.PrefixRange("1"), gomock.Any()).DoAndReturn(readRowsFrom(
+		&bt.Row{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}},
+		&bt.Row{Key: "10", Columns: []*bt.Column{diffColumn("d:row", "homura", 1)}},
+		&bt.Row{Key: "12", Columns: []*bt.Column{diffColumn("d:row", "sayaka", 1)}},
+	))
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users_v2", bigtable.PrefixRange("1"), gomock.Any()).DoAndReturn(readRowsFrom(
+		&bt.Row{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}},
+		&bt.Row{Key: "11", Columns: []*bt.Column{diffColumn("d:row", "kyouko", 1)}},
+		&bt.Row{Key: "12", Columns: []*bt.Column{diffColumn("d:row", "sayaka!", 1)}},
+	))
+
+	err := DoDiff(context.Background(), mockClient, "users", "users_v2", "prefix=1")
+	assert.NoError(t, err)
+	version := "1970/01/01-00:00:01.000000"
+	assert.Equal(t, "--- users\n+++ users_v2\n"+
+		"@@ 10\n- d:row                                    @ "+version+" \"homura\"\n"+
+		"@@ 11\n+ d:row                                    @ "+version+" \"kyouko\"\n"+
+		"@@ 12\n~ d:row                                    @ "+version+" \"sayaka\" -> \"sayaka!\"\n"+
+		"4 rows compared, 3 rows differ (1 only in users, 1 only in users_v2)\n"+
+		"1 cells added, 1 removed, 1 changed\n", buf.String())
+}
+
+func TestDoDiffRows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().Get(gomock.Any(), "users", "1", gomock.Any()).Return(&bt.Bigtable{
+		Table: "users",
+		Rows:  []*bt.Row{{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}}},
+	}, nil)
+	mockClient.EXPECT().Get(gomock.Any(), "users", "10", gomock.Any()).Return(&bt.Bigtable{
+		Table: "users",
+		Rows:  []*bt.Row{{Key: "10", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}}},
+	}, nil)
+
+	err := DoDiff(context.Background(), mockClient, "users", "1", "users", "10", "summary-only=true")
+	assert.NoError(t, err)
+	assert.Equal(t, "1 rows compared, 0 rows differ (0 only in users 1, 0 only in users 10)\n"+
+		"0 cells added, 0 removed, 0 changed\n", buf.String())
+}
+
+func TestDoDiffError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users", gomock.Any(), gomock.Any()).DoAndReturn(readRowsFrom(
+		&bt.Row{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}},
+	)).AnyTimes()
+	mockClient.EXPECT().ReadRows(gomock.Any(), "unknown", gomock.Any(), gomock.Any()).Return(errors.New("table not found")).AnyTimes()
+
+	err := DoDiff(context.Background(), mockClient, "users", "unknown")
+	assert.Error(t, err)
+	// the rows are not reported as added or removed
+	assert.Empty(t, buf.String())
+
+	for _, args := range [][]string{
+		{"users"},
+		{"users", "1", "users"},
+		{"users", "1", "users", "2", "prefix=1"},
+		{"users", "users", "prefix=1", "start=1"},
+	} {
+		err := DoDiff(context.Background(), mockClient, args...)
+		assert.Equal(t, KindUsage, KindOf(err), "args: %v", args)
+	}
+}
+
+func TestDoDiffProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users", gomock.Any(), gomock.Any()).DoAndReturn(readRowsFrom(
+		&bt.Row{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}},
+	))
+	stagingClient := bt.NewMockClient(ctrl)
+	stagingClient.EXPECT().ReadRows(gomock.Any(), "users", gomock.Any(), gomock.Any()).DoAndReturn(readRowsFrom(
+		&bt.Row{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}},
+	))
+
+	defer func() { ConnectProfile = nil }()
+	ConnectProfile = func(name string) (bt.Client, error) {
+		if name != "staging" {
+			return nil, NotFoundError("Profile not found: %s", name)
+		}
+		return stagingClient, nil
+	}
+
+	err := DoDiff(context.Background(), mockClient, "users", "staging:users")
+	assert.NoError(t, err)
+	assert.Equal(t, "1 rows compared, 0 rows differ (0 only in users, 0 only in staging:users)\n"+
+		"0 cells added, 0 removed, 0 changed\n", buf.String())
+
+	err = DoDiff(context.Background(), mockClient, "users", "prod:users")
+	assert.Equal(t, KindNotFound, KindOf(err))
+	err = DoDiff(context.Background(), mockClient, "users", ":users")
+	assert.Equal(t, KindUsage, KindOf(err))
+
+	ConnectProfile = nil
+	err = DoDiff(context.Background(), mockClient, "users", "staging:users")
+	assert.Equal(t, KindUsage, KindOf(err))
+}
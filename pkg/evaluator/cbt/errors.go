@@ -0,0 +1,96 @@
+package cbt
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorKind is the category of the command error
+type ErrorKind int
+
+// error categories
+const (
+	KindUsage ErrorKind = iota + 1
+	KindNotFound
+	KindPermission
+	KindDeadline
+	KindServer
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindUsage:
+		return "usage"
+	case KindNotFound:
+		return "not found"
+	case KindPermission:
+		return "permission"
+	case KindDeadline:
+		return "deadline"
+	default:
+		return "server"
+	}
+}
+
+// Error represents the error of the command with the category
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the underlying error
+func (e *Error) Cause() error {
+	return e.Err
+}
+
+// UsageError returns the error of the invalid arguments
+func UsageError(format string, args ...interface{}) error {
+	return &Error{Kind: KindUsage, Err: fmt.Errorf(format, args...)}
+}
+
+// NotFoundError returns the error of the missing resource
+func NotFoundError(format string, args ...interface{}) error {
+	return &Error{Kind: KindNotFound, Err: fmt.Errorf(format, args...)}
+}
+
+// WrapError categorizes the error by the gRPC status code of the Bigtable API.
+// Returns the error as is when already categorized.
+func WrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+	if err == context.DeadlineExceeded {
+		return &Error{Kind: KindDeadline, Err: err}
+	}
+
+	kind := KindServer
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.OutOfRange:
+		kind = KindUsage
+	case codes.NotFound:
+		kind = KindNotFound
+	case codes.PermissionDenied, codes.Unauthenticated:
+		kind = KindPermission
+	case codes.DeadlineExceeded:
+		kind = KindDeadline
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// KindOf returns the category of the error, uncategorized errors are treated as server errors.
+func KindOf(err error) ErrorKind {
+	if e, ok := WrapError(err).(*Error); ok {
+		return e.Kind
+	}
+	return KindServer
+}
@@ -0,0 +1,41 @@
+package cbt
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestKindOf(t *testing.T) {
+	cases := []struct {
+		err    error
+		expect ErrorKind
+	}{
+		{UsageError("Invalid args"), KindUsage},
+		{NotFoundError("Row not found"), KindNotFound},
+		{status.Error(codes.InvalidArgument, "bad filter"), KindUsage},
+		{status.Error(codes.NotFound, "table not found"), KindNotFound},
+		{status.Error(codes.PermissionDenied, "denied"), KindPermission},
+		{status.Error(codes.Unauthenticated, "no token"), KindPermission},
+		{status.Error(codes.DeadlineExceeded, "timeout"), KindDeadline},
+		{context.DeadlineExceeded, KindDeadline},
+		{status.Error(codes.Unavailable, "unavailable"), KindServer},
+		{errors.New("unknown"), KindServer},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expect, KindOf(c.err), "%v", c.err)
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	assert.Nil(t, WrapError(nil))
+
+	err := status.Error(codes.NotFound, "table not found")
+	wrapped := WrapError(err)
+	assert.Equal(t, err.Error(), wrapped.Error())
+	assert.Equal(t, wrapped, WrapError(wrapped))
+}
@@ -3,7 +3,9 @@ package cbt
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -13,140 +15,242 @@ import (
 	"github.com/takashabe/btcli/pkg/printer"
 )
 
-func DoLS(ctx context.Context, client bt.Client, args ...string) {
+func DoLS(ctx context.Context, client bt.Client, args ...string) error {
 	tables, err := client.Tables(ctx)
 	if err != nil {
-		fmt.Fprintf(client.ErrStream(), "%v", err)
-		return
+		return WrapError(err)
 	}
 	for _, tbl := range tables {
 		fmt.Fprintln(client.OutStream(), tbl)
 	}
+	return nil
 }
 
-func DoCount(ctx context.Context, client bt.Client, args ...string) {
+func DoCount(ctx context.Context, client bt.Client, args ...string) error {
 	if len(args) < 1 {
-		fmt.Fprintln(client.ErrStream(), "Invalid args: count <table>")
-		return
+		return UsageError("Invalid args: count <table> [args ...]")
 	}
 	table := args[0]
-	cnt, err := client.Count(ctx, table)
+	opts := args[1:]
+
+	parsed, err := CountOptions.Parse(opts)
+	if err != nil {
+		return err
+	}
+
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		return UsageError(`"start"/"end" may not be mixed with "prefix"`)
+	}
+
+	rr, err := rowRange(parsed)
+	if err != nil {
+		return UsageError("Invalid range: %v", err)
+	}
+	// only needs the row keys to count
+	parsed["keys-only"] = "true"
+	ro, err := readOption(parsed)
+	if err != nil {
+		return UsageError("Invalid options: %v", err)
+	}
+	byPrefix := parsed.Int("by-prefix")
+	parallelism := parsed.Int("parallelism")
+
+	var groupFn func(string) string
+	if byPrefix > 0 {
+		sep := parsed["separator"]
+		groupFn = func(key string) string {
+			return keyPrefix(key, int(byPrefix), sep)
+		}
+	}
+
+	var (
+		cnt  int
+		cnts map[string]int
+	)
+	switch {
+	case parallelism > 1:
+		begin, end := rowBounds(parsed)
+		cnt, cnts, err = parallelCount(ctx, client, table, begin, end, int(parallelism), groupFn, ro...)
+	case groupFn != nil:
+		cnts, err = client.CountBy(ctx, table, rr, groupFn, ro...)
+	default:
+		cnt, err = client.Count(ctx, table, rr, ro...)
+	}
 	if err != nil {
-		fmt.Fprintf(client.ErrStream(), "%v", err)
-		return
+		return WrapError(err)
 	}
-	fmt.Fprintln(client.OutStream(), cnt)
+
+	if groupFn == nil {
+		fmt.Fprintln(client.OutStream(), cnt)
+		return nil
+	}
+	prefixes := make([]string, 0, len(cnts))
+	for p := range cnts {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+	for _, p := range prefixes {
+		fmt.Fprintf(client.OutStream(), "%s\t%d\n", p, cnts[p])
+	}
+	return nil
 }
 
-func DoLookup(ctx context.Context, client bt.Client, args ...string) {
+// keyPrefix returns the first n characters of the key.
+// When sep is given, returns the first n segments separated by sep instead.
+func keyPrefix(key string, n int, sep string) string {
+	if sep == "" {
+		if len(key) <= n {
+			return key
+		}
+		return key[:n]
+	}
+
+	segs := strings.SplitN(key, sep, n+1)
+	if len(segs) <= n {
+		return key
+	}
+	return strings.Join(segs[:n], sep)
+}
+
+func DoLookup(ctx context.Context, client bt.Client, args ...string) error {
 	if len(args) < 2 {
-		fmt.Fprintln(client.ErrStream(), "Invalid args: lookup <table> <row>")
-		return
+		return UsageError("Invalid args: lookup <table> <row>")
 	}
 	table := args[0]
 	key := args[1]
 	opts := args[2:]
 
-	parsed := make(map[string]string)
-	for _, opt := range opts {
-		i := strings.Index(opt, "=")
-		if i < 0 {
-			fmt.Fprintf(client.ErrStream(), "Invalid option: %v\n", opt)
-			return
-		}
-		// TODO: Improve parsing opts
-		k, v := opt[:i], opt[i+1:]
-		switch k {
-		default:
-			fmt.Fprintf(client.ErrStream(), "Unknown option: %v\n", opt)
-			return
-		case "decode", "decode_columns":
-			parsed[k] = v
-		case "version":
-			parsed[k] = v
-		}
+	parsed, err := LookupOptions.Parse(opts)
+	if err != nil {
+		return err
 	}
 
 	ro, err := readOption(parsed)
 	if err != nil {
-		fmt.Fprintf(client.ErrStream(), "Invalid options: %v\n", err)
-		return
+		return UsageError("Invalid options: %v", err)
 	}
 
 	b, err := client.Get(ctx, table, key, ro...)
 	if err != nil {
-		fmt.Fprintf(client.OutStream(), "%v", err)
-		return
+		return WrapError(err)
 	}
 	row := b.Rows[0]
-
-	// decode options
-	p := &printer.Printer{
-		OutStream:        client.OutStream(),
-		DecodeType:       decodeGlobalOption(parsed),
-		DecodeColumnType: decodeColumnOption(parsed),
+	if len(row.Columns) == 0 {
+		return NotFoundError("Row not found: %s", key)
 	}
-	p.PrintRow(row)
+
+	NewPrinter(client.OutStream(), parsed).PrintRow(row)
+	return nil
 }
 
-func DoRead(ctx context.Context, client bt.Client, args ...string) {
+func DoRead(ctx context.Context, client bt.Client, args ...string) error {
 	if len(args) < 1 {
-		fmt.Fprintln(client.ErrStream(), "Invalid args: read <table> [args ...]")
-		return
+		return UsageError("Invalid args: read <table> [args ...]")
 	}
 	table := args[0]
 	opts := args[1:]
 
-	parsed := make(map[string]string)
-	for _, opt := range opts {
-		i := strings.Index(opt, "=")
-		if i < 0 {
-			fmt.Fprintf(os.Stderr, "Invalid option: %v\n", opt)
-			return
-		}
-		// TODO: Improve parsing opts
-		key, val := opt[:i], opt[i+1:]
-		switch key {
-		default:
-			fmt.Fprintf(os.Stderr, "Unknown option: %v\n", opt)
-			return
-		case "decode", "decode_columns":
-			parsed[key] = val
-		case "count", "start", "end", "prefix", "version", "family", "value", "from", "to":
-			parsed[key] = val
-		}
+	parsed, err := ReadOptions.Parse(opts)
+	if err != nil {
+		return err
 	}
 
 	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
-		fmt.Fprintf(client.ErrStream(), `"start"/"end" may not be mixed with "prefix"`)
-		return
+		return UsageError(`"start"/"end" may not be mixed with "prefix"`)
 	}
 
-	rr, err := rowRange(parsed)
-	if err != nil {
-		fmt.Fprintf(client.ErrStream(), "Invlaid range: %v\n", err)
-		return
+	return doRead(ctx, client, table, parsed)
+}
+
+// DoNext continues the last read query from just after the last returned row.
+func DoNext(ctx context.Context, client bt.Client, args ...string) error {
+	if lastCursor == nil {
+		return UsageError("No previous read query")
 	}
-	ro, err := readOption(parsed)
-	if err != nil {
-		fmt.Fprintf(client.ErrStream(), "Invalid options: %v\n", err)
-		return
+	if lastCursor.done {
+		return UsageError("No more rows")
 	}
+	return doRead(ctx, client, lastCursor.table, lastCursor.nextArgs())
+}
 
-	b, err := client.GetRows(ctx, table, rr, ro...)
-	if err != nil {
-		fmt.Fprintf(client.ErrStream(), "%v\n", err)
-		return
+func doRead(ctx context.Context, client bt.Client, table string, parsed Values) error {
+	limit := parsed.Int("count")
+	pageSize := parsed.Int("page-size")
+	parallelism := parsed.Int("parallelism")
+	ordered := parsed.Bool("ordered")
+
+	p := NewPrinter(client.OutStream(), parsed)
+
+	cur := &readCursor{
+		table:  table,
+		parsed: parsed,
 	}
-	rows := b.Rows
+	lastCursor = cur
 
-	// decode options
-	p := &printer.Printer{
-		OutStream:        client.OutStream(),
-		DecodeType:       decodeGlobalOption(parsed),
-		DecodeColumnType: decodeColumnOption(parsed),
+	if parallelism > 1 {
+		if pageSize > 0 {
+			return UsageError(`"page-size" may not be mixed with "parallelism"`)
+		}
+		ro, err := readOption(parsed)
+		if err != nil {
+			return UsageError("Invalid options: %v", err)
+		}
+		begin, end := rowBounds(parsed)
+		n, lastKey, err := parallelRead(ctx, client, table, begin, end, int(parallelism), ordered, limit, p, ro...)
+		if err != nil {
+			return WrapError(err)
+		}
+		// continue only the ordered results, unordered results have no position
+		cur.lastKey = lastKey
+		cur.done = !ordered || limit == 0 || n < limit
+		return nil
+	}
+
+	args := parsed
+	var total int64
+	for {
+		pageLimit := limit - total
+		if pageSize > 0 && (limit == 0 || pageSize < pageLimit) {
+			pageLimit = pageSize
+		}
+		pageArgs := copyArgs(args)
+		if pageLimit > 0 {
+			pageArgs["count"] = strconv.FormatInt(pageLimit, 10)
+		}
+
+		rr, err := rowRange(pageArgs)
+		if err != nil {
+			return UsageError("Invalid range: %v", err)
+		}
+		ro, err := readOption(pageArgs)
+		if err != nil {
+			return UsageError("Invalid options: %v", err)
+		}
+
+		b, err := client.GetRows(ctx, table, rr, ro...)
+		if err != nil {
+			return WrapError(err)
+		}
+		rows := b.Rows
+		p.PrintRows(rows)
+
+		total += int64(len(rows))
+		if len(rows) > 0 {
+			cur.lastKey = rows[len(rows)-1].Key
+		}
+		if pageLimit <= 0 || int64(len(rows)) < pageLimit {
+			cur.done = true
+			return nil
+		}
+		if limit > 0 && total >= limit {
+			return nil
+		}
+		// the redirected output is read through without waiting, the prompt is not written to the file
+		if IsTerminal(client.OutStream()) && !waitMore(client.ErrStream()) {
+			return nil
+		}
+		args = cur.nextArgs()
 	}
-	p.PrintRows(rows)
 }
 
 func rowRange(parsedArgs map[string]string) (bigtable.RowRange, error) {
@@ -163,13 +267,39 @@ func rowRange(parsedArgs map[string]string) (bigtable.RowRange, error) {
 	return rr, nil
 }
 
+// rowBounds returns the begin and end keys of the range. Empty end means infinity.
+func rowBounds(parsedArgs map[string]string) (string, string) {
+	if prefix := parsedArgs["prefix"]; prefix != "" {
+		return prefix, prefixSuccessor(prefix)
+	}
+	return parsedArgs["start"], parsedArgs["end"]
+}
+
 func readOption(parsedArgs map[string]string) ([]bigtable.ReadOption, error) {
-	var (
-		opts []bigtable.ReadOption
-		fils []bigtable.Filter
-	)
+	var opts []bigtable.ReadOption
+
+	fils, err := readFilters(parsedArgs)
+	if err != nil {
+		return nil, err
+	}
+	if f := chainFilters(fils); f != nil {
+		opts = append(opts, bigtable.RowFilter(f))
+	}
+
+	// isolated readOption
+	if count := parsedArgs["count"]; count != "" {
+		n, err := strconv.ParseInt(count, 0, 64)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, bigtable.LimitRows(n))
+	}
+	return opts, nil
+}
 
-	// filters
+// readFilters returns the filters of the options.
+func readFilters(parsedArgs map[string]string) ([]bigtable.Filter, error) {
+	var fils []bigtable.Filter
 	if regex := parsedArgs["regex"]; regex != "" {
 		fils = append(fils, bigtable.RowKeyFilter(regex))
 	}
@@ -204,22 +334,36 @@ func readOption(parsedArgs map[string]string) ([]bigtable.ReadOption, error) {
 	if value := parsedArgs["value"]; value != "" {
 		fils = append(fils, bigtable.ValueFilter(fmt.Sprintf("%s", value)))
 	}
+	if Values(parsedArgs).Bool("keys-only") {
+		// a single stripped cell is enough to know the row exists
+		fils = append(fils, bigtable.StripValueFilter(), bigtable.CellsPerRowLimitFilter(1))
+	} else if Values(parsedArgs).Bool("strip-values") {
+		fils = append(fils, bigtable.StripValueFilter())
+	}
+	return fils, nil
+}
 
-	if len(fils) == 1 {
-		opts = append(opts, bigtable.RowFilter(fils[0]))
-	} else if len(fils) > 1 {
-		opts = append(opts, bigtable.RowFilter(bigtable.ChainFilters(fils...)))
+// chainFilters returns the filter applying all the filters, or nil when no filters are given.
+func chainFilters(fils []bigtable.Filter) bigtable.Filter {
+	switch len(fils) {
+	case 0:
+		return nil
+	case 1:
+		return fils[0]
+	default:
+		return bigtable.ChainFilters(fils...)
 	}
+}
 
-	// isolated readOption
-	if count := parsedArgs["count"]; count != "" {
-		n, err := strconv.ParseInt(count, 0, 64)
-		if err != nil {
-			return nil, err
-		}
-		opts = append(opts, bigtable.LimitRows(n))
+// NewPrinter returns the printer with the decode and the print options.
+func NewPrinter(w io.Writer, parsed Values) *printer.Printer {
+	return &printer.Printer{
+		OutStream:        w,
+		DecodeType:       decodeGlobalOption(parsed),
+		DecodeColumnType: decodeColumnOption(parsed),
+		KeysOnly:         parsed.Bool("keys-only"),
+		StripValues:      parsed.Bool("strip-values"),
 	}
-	return opts, nil
 }
 
 func decodeGlobalOption(parsedArgs map[string]string) string {
@@ -230,7 +374,7 @@ func decodeGlobalOption(parsedArgs map[string]string) string {
 }
 
 func decodeColumnOption(parsedArgs map[string]string) map[string]string {
-	arg := parsedArgs["decode_columns"]
+	arg := parsedArgs["decode-columns"]
 	if len(arg) == 0 {
 		return map[string]string{}
 	}
@@ -3,6 +3,7 @@ package cbt
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -79,6 +80,29 @@ func TestReadOption(t *testing.T) {
 				)),
 			},
 		},
+		{
+			map[string]string{
+				"keys-only": "true",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.ChainFilters(
+					bigtable.StripValueFilter(),
+					bigtable.CellsPerRowLimitFilter(1),
+				)),
+			},
+		},
+		{
+			map[string]string{
+				"family":       "d",
+				"strip-values": "true",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.ChainFilters(
+					bigtable.FamilyFilter("^d$"),
+					bigtable.StripValueFilter(),
+				)),
+			},
+		},
 	}
 	for _, c := range cases {
 		actual, err := readOption(c.input)
@@ -233,9 +257,62 @@ func TestDoCountExecutor(t *testing.T) {
 			[]string{"table"},
 			"1\n",
 			func(mock *bt.MockClient) {
-				mock.EXPECT().Count(gomock.Any(), "table").Return(1, nil)
+				mock.EXPECT().Count(
+					gomock.Any(),
+					"table",
+					bigtable.RowRange{},
+					filtersToReadOption(
+						bigtable.StripValueFilter(),
+						bigtable.CellsPerRowLimitFilter(1),
+					),
+				).Return(1, nil)
+			},
+		},
+		{
+			[]string{"table", "prefix=a", "family=d"},
+			"2\n",
+			func(mock *bt.MockClient) {
+				mock.EXPECT().Count(
+					gomock.Any(),
+					"table",
+					bigtable.PrefixRange("a"),
+					filtersToReadOption(
+						bigtable.FamilyFilter("^d$"),
+						bigtable.StripValueFilter(),
+						bigtable.CellsPerRowLimitFilter(1),
+					),
+				).Return(2, nil)
 			},
 		},
+		{
+			[]string{"table", "by-prefix=1"},
+			"a\t2\nb\t1\n",
+			func(mock *bt.MockClient) {
+				mock.EXPECT().CountBy(
+					gomock.Any(),
+					"table",
+					bigtable.RowRange{},
+					gomock.Any(),
+					gomock.Any(),
+				).Return(map[string]int{"b": 1, "a": 2}, nil)
+			},
+		},
+		{
+			[]string{"table", "prefix=a", "parallelism=2", "by-prefix=2"},
+			"a1\t2\na2\t1\n",
+			func(mock *bt.MockClient) {
+				mock.EXPECT().SampleRowKeys(gomock.Any(), "table").Return([]string{"a2"}, nil)
+				mock.EXPECT().ReadRows(gomock.Any(), "table", bigtable.NewRange("a", "a2"), gomock.Any(), gomock.Any()).
+					DoAndReturn(readRowsFn("a1", "a10"))
+				mock.EXPECT().ReadRows(gomock.Any(), "table", bigtable.NewRange("a2", "b"), gomock.Any(), gomock.Any()).
+					DoAndReturn(readRowsFn("a2"))
+			},
+		},
+		{
+			[]string{"table", "prefix=a", "start=b"},
+			"\"start\"/\"end\" may not be mixed with \"prefix\"\n",
+			func(mock *bt.MockClient) {},
+		},
 	}
 	for _, c := range cases {
 		mockClient := bt.NewMockClient(ctrl)
@@ -245,7 +322,53 @@ func TestDoCountExecutor(t *testing.T) {
 		mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
 		mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
 
-		DoCount(context.Background(), mockClient, c.input...)
+		if err := DoCount(context.Background(), mockClient, c.input...); err != nil {
+			fmt.Fprintln(&buf, err)
+		}
 		assert.Equal(t, c.expect, buf.String())
 	}
 }
+
+func readRowsFn(keys ...string) interface{} {
+	return func(_ context.Context, _ string, _ bigtable.RowRange, fn func(*bt.Row) bool, _ ...bigtable.ReadOption) error {
+		for _, k := range keys {
+			if !fn(&bt.Row{Key: k}) {
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+func TestDoLookupNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	mockClient.EXPECT().Get(gomock.Any(), "table", "missing").
+		Return(&bt.Bigtable{Table: "table", Rows: []*bt.Row{{Key: "missing"}}}, nil)
+
+	err := DoLookup(context.Background(), mockClient, "table", "missing")
+	assert.EqualError(t, err, "Row not found: missing")
+	assert.Equal(t, KindNotFound, KindOf(err))
+}
+
+func TestKeyPrefix(t *testing.T) {
+	cases := []struct {
+		key    string
+		n      int
+		sep    string
+		expect string
+	}{
+		{"1##2##3", 1, "", "1"},
+		{"1##2##3", 3, "", "1##"},
+		{"1", 3, "", "1"},
+		{"1##2##3", 1, "##", "1"},
+		{"1##2##3", 2, "##", "1##2"},
+		{"1##2##3", 3, "##", "1##2##3"},
+		{"1##2##3", 4, "##", "1##2##3"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expect, keyPrefix(c.key, c.n, c.sep))
+	}
+}
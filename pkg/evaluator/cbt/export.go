@@ -0,0 +1,312 @@
+package cbt
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/printer"
+)
+
+// export file formats
+const (
+	exportJSONL = "jsonl"
+	exportCSV   = "csv"
+)
+
+// csvHeader is the first record of the exported CSV, a record per cell
+var csvHeader = []string{"key", "family", "qualifier", "timestamp", "value"}
+
+// DoExport writes the rows to the file as JSON Lines or CSV.
+// The values are base64 encoded unless the decode options are given.
+// The rows are written in the key order even with the parallel scan, so resume=true continues from the last exported row.
+func DoExport(ctx context.Context, client bt.Client, args ...string) error {
+	if len(args) < 2 {
+		return UsageError("Invalid args: export <table> <file> [args ...]")
+	}
+	table, path := args[0], args[1]
+	parsed, err := ExportOptions.Parse(args[2:])
+	if err != nil {
+		return err
+	}
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		return UsageError(`"start"/"end" may not be mixed with "prefix"`)
+	}
+
+	w, lastKey, err := openExport(path, parsed)
+	if err != nil {
+		return err
+	}
+	if lastKey != "" {
+		fmt.Fprintf(client.ErrStream(), "Resuming from %s\n", lastKey)
+		parsed = resumeArgs(parsed, lastKey)
+	}
+	rr, err := rowRange(parsed)
+	if err != nil {
+		w.close()
+		return UsageError("Invalid range: %v", err)
+	}
+	ro, err := readOption(parsed)
+	if err != nil {
+		w.close()
+		return UsageError("Invalid options: %v", err)
+	}
+
+	ctx, stop := WithInterrupt(ctx)
+	defer stop()
+
+	var (
+		exported int64
+		writeErr error
+	)
+	write := func(r *bt.Row) bool {
+		if writeErr = w.write(r); writeErr != nil {
+			return false
+		}
+		atomic.AddInt64(&exported, 1)
+		return true
+	}
+	stopProgress := showProgress(client.ErrStream(), "exported", &exported)
+	if parallelism := parsed.Int("parallelism"); parallelism > 1 {
+		begin, end := rowBounds(parsed)
+		err = bt.ParallelScanOrdered(ctx, client, table, begin, end, int(parallelism), write, ro...)
+	} else {
+		err = client.ReadRows(ctx, table, rr, write, ro...)
+	}
+	stopProgress()
+	if cerr := w.close(); writeErr == nil {
+		writeErr = cerr
+	}
+
+	fmt.Fprintf(client.OutStream(), "%d rows exported to %s\n", atomic.LoadInt64(&exported), path)
+	if writeErr == nil && err == nil {
+		return nil
+	}
+	fmt.Fprintln(client.ErrStream(), "Export is interrupted, continue with resume=true")
+	switch {
+	case writeErr != nil:
+		return WrapError(fmt.Errorf("Failed to write %s: %v", path, writeErr))
+	case ctx.Err() != nil:
+		// interrupted by Ctrl-C
+		return nil
+	default:
+		return WrapError(err)
+	}
+}
+
+// resumeArgs returns the parsed args to read the rows from the key, the prefix is replaced with the range.
+func resumeArgs(parsed Values, key string) Values {
+	next := copyArgs(parsed)
+	if prefix := next["prefix"]; prefix != "" {
+		delete(next, "prefix")
+		next["end"] = prefixSuccessor(prefix)
+	}
+	next["start"] = key
+	return next
+}
+
+// exportWriter writes the rows in the format
+type exportWriter struct {
+	file *os.File
+	// json or csv is set by the format
+	buf  *bufio.Writer
+	json *json.Encoder
+	csv  *csv.Writer
+
+	// decoder decides the decode type of the column, the values without the type are base64 encoded
+	decoder *printer.Printer
+}
+
+// openExport opens the file to export, and returns the last exported key when resuming.
+// The records of the last key are removed to write the row again, because the row may be written partially.
+func openExport(path string, parsed Values) (*exportWriter, string, error) {
+	format := parsed["format"]
+	var (
+		offset  int64
+		lastKey string
+	)
+	if parsed.Bool("resume") {
+		var err error
+		offset, lastKey, err = lastExported(path, format)
+		if err != nil {
+			return nil, "", UsageError("Can not resume %s: %v", path, err)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, "", UsageError("Failed to open %s: %v", path, err)
+	}
+	if err := f.Truncate(offset); err != nil {
+		f.Close()
+		return nil, "", UsageError("Failed to open %s: %v", path, err)
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, "", UsageError("Failed to open %s: %v", path, err)
+	}
+
+	w := &exportWriter{
+		file: f,
+		// the decode type of the environment is not applied, to keep the raw values by default
+		decoder: &printer.Printer{
+			DecodeType:       parsed["decode"],
+			DecodeColumnType: decodeColumnOption(parsed),
+		},
+	}
+	switch format {
+	case exportCSV:
+		w.csv = csv.NewWriter(f)
+		if offset == 0 {
+			w.csv.Write(csvHeader)
+		}
+	default:
+		w.buf = bufio.NewWriter(f)
+		w.json = json.NewEncoder(w.buf)
+		w.json.SetEscapeHTML(false)
+	}
+	return w, lastKey, nil
+}
+
+type exportCell struct {
+	Family    string      `json:"family"`
+	Qualifier string      `json:"qualifier"`
+	Timestamp int64       `json:"timestamp"`
+	Value     interface{} `json:"value"`
+}
+
+type exportRow struct {
+	Key   string       `json:"key"`
+	Cells []exportCell `json:"cells"`
+}
+
+func (w *exportWriter) write(r *bt.Row) error {
+	row := exportRow{Key: r.Key, Cells: make([]exportCell, 0, len(r.Columns))}
+	for _, c := range r.Columns {
+		family, column := splitColumn(c)
+		row.Cells = append(row.Cells, exportCell{
+			Family:    family,
+			Qualifier: column,
+			// microseconds like the timestamp of Bigtable
+			Timestamp: c.Version.UnixNano() / 1000,
+			Value:     w.value(c),
+		})
+	}
+
+	if w.csv == nil {
+		return w.json.Encode(row)
+	}
+	for _, c := range row.Cells {
+		rec := []string{row.Key, c.Family, c.Qualifier, fmt.Sprint(c.Timestamp), fmt.Sprint(c.Value)}
+		if err := w.csv.Write(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *exportWriter) value(c *bt.Column) interface{} {
+	decode := w.decoder.ColumnDecodeType(c.Qualifier)
+	if decode == "" {
+		return base64.StdEncoding.EncodeToString(c.Value)
+	}
+	return printer.Decode(decode, c.Value)
+}
+
+// close flushes the buffered rows and closes the file.
+func (w *exportWriter) close() error {
+	var err error
+	if w.csv != nil {
+		w.csv.Flush()
+		err = w.csv.Error()
+	} else {
+		err = w.buf.Flush()
+	}
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// lastExported returns the offset of the first record of the last exported key and the key.
+// The broken record at the end by the interruption is also removed from the offset.
+func lastExported(path, format string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, "", nil
+		}
+		return 0, "", err
+	}
+	defer f.Close()
+
+	var (
+		offset  int64
+		lastKey string
+		next    func() (int64, string, error)
+	)
+	r := bufio.NewReader(f)
+	switch format {
+	case exportCSV:
+		cr := csv.NewReader(r)
+		header, err := cr.Read()
+		if err == io.EOF {
+			return 0, "", nil
+		}
+		if err != nil || fmt.Sprint(header) != fmt.Sprint(csvHeader) {
+			return 0, "", fmt.Errorf("not the csv exported by btcli")
+		}
+		offset = cr.InputOffset()
+		next = func() (int64, string, error) {
+			off := cr.InputOffset()
+			rec, err := cr.Read()
+			if err != nil {
+				return off, "", err
+			}
+			return off, rec[0], nil
+		}
+	default:
+		// a row per line, the newlines in the strings are escaped
+		var pos int64
+		next = func() (int64, string, error) {
+			off := pos
+			line, err := r.ReadBytes('\n')
+			pos += int64(len(line))
+			if err == io.EOF && len(line) > 0 {
+				return off, "", io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return off, "", err
+			}
+			var row exportRow
+			if err := json.Unmarshal(line, &row); err != nil {
+				return off, "", err
+			}
+			return off, row.Key, nil
+		}
+	}
+
+	for {
+		off, key, err := next()
+		if err == io.EOF {
+			return offset, lastKey, nil
+		}
+		if err != nil {
+			// only the last record may be broken by the interruption
+			if _, _, err := next(); err != io.EOF {
+				return 0, "", fmt.Errorf("not the %s exported by btcli", format)
+			}
+			return offset, lastKey, nil
+		}
+		if key != lastKey {
+			offset, lastKey = off, key
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package cbt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+// exportRows is the rows of users to export
+var exportRows = []*bt.Row{
+	{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}},
+	{Key: "10", Columns: []*bt.Column{diffColumn("d:age", "\x00\x00\x00\x00\x00\x00\x00\x0e", 1), diffColumn("d:row", "homura", 1)}},
+	{Key: "2", Columns: []*bt.Column{diffColumn("d:row", "sayaka", 2)}},
+}
+
+const (
+	exportLine1  = `{"key":"1","cells":[{"family":"d","qualifier":"row","timestamp":1000000,"value":"bWFkb2th"}]}` + "\n"
+	exportLine10 = `{"key":"10","cells":[{"family":"d","qualifier":"age","timestamp":1000000,"value":"AAAAAAAAAA4="},{"family":"d","qualifier":"row","timestamp":1000000,"value":"aG9tdXJh"}]}` + "\n"
+	exportLine2  = `{"key":"2","cells":[{"family":"d","qualifier":"row","timestamp":2000000,"value":"c2F5YWth"}]}` + "\n"
+)
+
+func exportDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestDoExport(t *testing.T) {
+	dir, cleanup := exportDir(t)
+	defer cleanup()
+
+	cases := []struct {
+		args   []string
+		expect string
+	}{
+		{
+			nil,
+			exportLine1 + exportLine10 + exportLine2,
+		},
+		{
+			[]string{"format=csv", "decode=string", "decode-columns=age:int"},
+			"key,family,qualifier,timestamp,value\n" +
+				"1,d,row,1000000,madoka\n" +
+				"10,d,age,1000000,14\n" +
+				"10,d,row,1000000,homura\n" +
+				"2,d,row,2000000,sayaka\n",
+		},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+
+		mockClient := bt.NewMockClient(ctrl)
+		var buf bytes.Buffer
+		mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+		mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+		mockClient.EXPECT().ReadRows(gomock.Any(), "users", bigtable.RowRange{}, gomock.Any(), gomock.Any()).DoAndReturn(readRowsFrom(exportRows...))
+
+		path := filepath.Join(dir, "users")
+		err := DoExport(context.Background(), mockClient, append([]string{"users", path}, c.args...)...)
+		assert.NoError(t, err, "args: %v", c.args)
+		assert.Equal(t, "3 rows exported to "+path+"\n", buf.String())
+
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expect, string(data), "args: %v", c.args)
+		ctrl.Finish()
+	}
+}
+
+func TestDoExportParallel(t *testing.T) {
+	dir, cleanup := exportDir(t)
+	defer cleanup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().SampleRowKeys(gomock.Any(), "users").Return([]string{"10", "2"}, nil)
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users", gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, rr bigtable.RowRange, fn func(*bt.Row) bool, _ ...bigtable.ReadOption) error {
+			for _, r := range exportRows {
+				if rr.Contains(r.Key) && !fn(r) {
+					break
+				}
+			}
+			return nil
+		}).Times(3)
+
+	path := filepath.Join(dir, "users.jsonl")
+	err := DoExport(context.Background(), mockClient, "users", path, "parallelism=3")
+	assert.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, exportLine1+exportLine10+exportLine2, string(data))
+}
+
+func TestDoExportResume(t *testing.T) {
+	dir, cleanup := exportDir(t)
+	defer cleanup()
+
+	cases := []struct {
+		written string
+		start   string
+	}{
+		// the last row is written again
+		{exportLine1 + exportLine10, "10"},
+		// broken by the interruption
+		{exportLine1 + exportLine10 + exportLine2[:10], "10"},
+		{exportLine1[:10], ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+
+		mockClient := bt.NewMockClient(ctrl)
+		var buf bytes.Buffer
+		mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+		mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+		rr := bigtable.RowRange{}
+		rows := exportRows
+		if c.start != "" {
+			rr = bigtable.InfiniteRange(c.start)
+			rows = exportRows[1:]
+		}
+		mockClient.EXPECT().ReadRows(gomock.Any(), "users", rr, gomock.Any(), gomock.Any()).DoAndReturn(readRowsFrom(rows...))
+
+		path := filepath.Join(dir, "users.jsonl")
+		if err := ioutil.WriteFile(path, []byte(c.written), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := DoExport(context.Background(), mockClient, "users", path, "resume=true")
+		assert.NoError(t, err, "written: %q", c.written)
+
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, exportLine1+exportLine10+exportLine2, string(data), "written: %q", c.written)
+		ctrl.Finish()
+	}
+}
+
+func TestDoExportError(t *testing.T) {
+	dir, cleanup := exportDir(t)
+	defer cleanup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users", gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ bigtable.RowRange, fn func(*bt.Row) bool, _ ...bigtable.ReadOption) error {
+			fn(exportRows[0])
+			return errors.New("unavailable")
+		})
+
+	path := filepath.Join(dir, "users.jsonl")
+	err := DoExport(context.Background(), mockClient, "users", path)
+	assert.Equal(t, KindServer, KindOf(err))
+	assert.Equal(t, "1 rows exported to "+path+"\nExport is interrupted, continue with resume=true\n", buf.String())
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, exportLine1, string(data))
+
+	for _, args := range [][]string{
+		{"users"},
+		{"users", path, "format=xml"},
+		{"users", path, "prefix=1", "start=1"},
+		// not the csv
+		{"users", path, "format=csv", "resume=true"},
+		{"users", filepath.Join(dir, "none", "users.jsonl")},
+	} {
+		err := DoExport(context.Background(), mockClient, args...)
+		assert.Equal(t, KindUsage, KindOf(err), "args: %v", args)
+	}
+}
@@ -0,0 +1,384 @@
+package cbt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/takashabe/btcli/pkg/printer"
+)
+
+// OptionType is the type of the option value
+type OptionType int
+
+// option value types
+const (
+	TypeString OptionType = iota
+	TypeInt
+	TypeFloat
+	TypeBool
+	TypeDuration
+)
+
+func (t OptionType) String() string {
+	switch t {
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeBool:
+		return "bool"
+	case TypeDuration:
+		return "duration"
+	default:
+		return "string"
+	}
+}
+
+// completion sources of the option value
+const (
+	CompleteFamily        = "family"
+	CompleteRowKey        = "row"
+	CompleteDecodeColumns = "decode-columns"
+)
+
+// Option describes a "key=value" option of the command
+type Option struct {
+	Name        string
+	Aliases     []string
+	Description string
+
+	Type    OptionType
+	Default string
+	// Values restricts the value to these candidates, and is also used for the completion
+	Values []string
+	// Validate validates the value after the type conversion
+	Validate func(v string) error
+	// Complete is the source to complete the value, one of the Complete* constants
+	Complete string
+}
+
+// Candidates returns the candidates of the value.
+func (o Option) Candidates() []string {
+	if len(o.Values) > 0 {
+		return o.Values
+	}
+	if o.Type == TypeBool {
+		return []string{"true", "false"}
+	}
+	return nil
+}
+
+// check validates the value with the type, candidates and Validate.
+func (o Option) check(v string) error {
+	var err error
+	switch o.Type {
+	case TypeInt:
+		_, err = strconv.ParseInt(v, 0, 64)
+	case TypeFloat:
+		_, err = strconv.ParseFloat(v, 64)
+	case TypeBool:
+		_, err = strconv.ParseBool(v)
+	case TypeDuration:
+		_, err = time.ParseDuration(v)
+	}
+	if err != nil {
+		return UsageError("Invalid value: %s=%s, must be %s", o.Name, v, o.Type)
+	}
+
+	if len(o.Values) > 0 && !contains(o.Values, v) {
+		return UsageError("Invalid value: %s=%s, must be one of <%s>", o.Name, v, strings.Join(o.Values, "|"))
+	}
+	if o.Validate != nil {
+		if err := o.Validate(v); err != nil {
+			return UsageError("Invalid value: %s=%s, %v", o.Name, v, err)
+		}
+	}
+	return nil
+}
+
+// help returns the description with the candidates and the default value.
+func (o Option) help() string {
+	h := o.Description
+	if cs := o.Candidates(); len(cs) > 0 {
+		h += fmt.Sprintf(". <%s>", strings.Join(cs, "|"))
+	}
+	if o.Default != "" {
+		h += fmt.Sprintf(", default is %s", o.Default)
+	}
+	return h
+}
+
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Options is the list of the options that the command accepts
+type Options []Option
+
+// Lookup returns the option matched with the name or the aliases.
+func (opts Options) Lookup(key string) (Option, bool) {
+	for _, o := range opts {
+		if o.Name == key {
+			return o, true
+		}
+		for _, a := range o.Aliases {
+			if a == key {
+				return o, true
+			}
+		}
+	}
+	return Option{}, false
+}
+
+// Parse parses and validates "key=value" args to the values keyed by the option name.
+// Omitted options are filled with the default values.
+func (opts Options) Parse(args []string) (Values, error) {
+	parsed := make(Values)
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			return nil, UsageError("Invalid option: %v", arg)
+		}
+		o, ok := opts.Lookup(arg[:i])
+		if !ok {
+			return nil, UsageError("Unknown option: %v", arg)
+		}
+		v := arg[i+1:]
+		if err := o.check(v); err != nil {
+			return nil, err
+		}
+		parsed[o.Name] = v
+	}
+
+	for _, o := range opts {
+		if _, ok := parsed[o.Name]; !ok && o.Default != "" {
+			parsed[o.Name] = o.Default
+		}
+	}
+	return parsed, nil
+}
+
+// Split splits the args into the positional arguments and the "key=value" options.
+// The argument is an option only when the key is the name of the option, so the positional argument may contain "=", e.g. the row "k=1".
+func (opts Options) Split(args []string) (positional, options []string) {
+	for _, a := range args {
+		if i := strings.Index(a, "="); i >= 0 {
+			if _, ok := opts.Lookup(a[:i]); ok {
+				options = append(options, a)
+				continue
+			}
+		}
+		positional = append(positional, a)
+	}
+	return positional, options
+}
+
+// Usage returns the description lines of the options.
+func (opts Options) Usage() string {
+	lines := make([]string, 0, len(opts))
+	for _, o := range opts {
+		lines = append(lines, fmt.Sprintf("\t%-14s %s", o.Name, o.help()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Values is the parsed options keyed by the option name.
+// The typed getters return zero value when the value is omitted.
+type Values map[string]string
+
+// Int returns the value as an integer.
+func (v Values) Int(name string) int64 {
+	n, _ := strconv.ParseInt(v[name], 0, 64)
+	return n
+}
+
+// Float returns the value as a float.
+func (v Values) Float(name string) float64 {
+	f, _ := strconv.ParseFloat(v[name], 64)
+	return f
+}
+
+// Bool returns the value as a bool.
+func (v Values) Bool(name string) bool {
+	b, _ := strconv.ParseBool(v[name])
+	return b
+}
+
+// Duration returns the value as a duration.
+func (v Values) Duration(name string) time.Duration {
+	d, _ := time.ParseDuration(v[name])
+	return d
+}
+
+func nonNegative(v string) error {
+	if n, _ := strconv.ParseFloat(v, 64); n < 0 {
+		return fmt.Errorf("must not be negative")
+	}
+	return nil
+}
+
+func positive(v string) error {
+	if n, _ := strconv.ParseFloat(v, 64); n <= 0 {
+		return fmt.Errorf("must be positive")
+	}
+	return nil
+}
+
+func positiveDuration(v string) error {
+	if d, _ := time.ParseDuration(v); d <= 0 {
+		return fmt.Errorf("must be positive")
+	}
+	return nil
+}
+
+func ratio(v string) error {
+	if p, _ := strconv.ParseFloat(v, 64); p <= 0 || p > 1 {
+		return fmt.Errorf("must be in (0, 1]")
+	}
+	return nil
+}
+
+var (
+	decodeOption = Option{
+		Name:        "decode",
+		Description: "Decode big-endian value",
+		Values:      printer.DecodeTypes(),
+	}
+	decodeColumnsOption = Option{
+		Name:        "decode-columns",
+		Aliases:     []string{"decode_columns"},
+		Description: "Decode big-endian value with columns. <column_name:<string|int|float>[,<column_name:...>]",
+		Complete:    CompleteDecodeColumns,
+	}
+)
+
+// LookupOptions is the options of the lookup command
+var LookupOptions = Options{
+	{Name: "family", Description: "Read only columns family with <columns_family>", Complete: CompleteFamily},
+	{Name: "version", Description: "Read only latest <n> columns", Type: TypeInt, Validate: nonNegative},
+	decodeOption,
+	decodeColumnsOption,
+}
+
+// ReadOptions is the options of the read command
+var ReadOptions = Options{
+	{Name: "start", Description: "Start reading at this row", Complete: CompleteRowKey},
+	{Name: "end", Description: "Stop reading before this row", Complete: CompleteRowKey},
+	{Name: "prefix", Description: "Read rows with this prefix", Complete: CompleteRowKey},
+	{Name: "regex", Description: "Read rows whose key matches this regex"},
+	{Name: "value", Description: "Read rows with has value"},
+	{Name: "family", Description: "Read only columns family with <columns_family>", Complete: CompleteFamily},
+	{Name: "version", Description: "Read only latest <n> columns", Type: TypeInt, Validate: nonNegative},
+	{Name: "from", Description: "Read newer cells than this unixtime", Type: TypeInt},
+	{Name: "to", Description: "Read older cells than this unixtime", Type: TypeInt},
+	{Name: "count", Description: "Read only <n> rows", Type: TypeInt, Validate: nonNegative},
+	{Name: "page-size", Description: "Read <n> rows per page and wait for a key input between pages", Type: TypeInt, Validate: nonNegative},
+	{Name: "parallelism", Description: "Read with <n> workers splitting the table by the sampled row keys", Type: TypeInt, Validate: nonNegative},
+	{Name: "ordered", Description: "Print the rows in the key order with parallelism", Type: TypeBool, Default: "true"},
+	{Name: "keys-only", Description: "Read only row keys", Type: TypeBool},
+	{Name: "strip-values", Description: "Read columns without the values", Type: TypeBool},
+	decodeOption,
+	decodeColumnsOption,
+}
+
+// CountOptions is the options of the count command
+var CountOptions = Options{
+	{Name: "start", Description: "Start counting at this row", Complete: CompleteRowKey},
+	{Name: "end", Description: "Stop counting before this row", Complete: CompleteRowKey},
+	{Name: "prefix", Description: "Count rows with this prefix", Complete: CompleteRowKey},
+	{Name: "regex", Description: "Count rows whose key matches this regex"},
+	{Name: "value", Description: "Count rows with has value"},
+	{Name: "family", Description: "Count rows which have columns family with <columns_family>", Complete: CompleteFamily},
+	{Name: "version", Description: "Count only latest <n> columns", Type: TypeInt, Validate: nonNegative},
+	{Name: "from", Description: "Count rows which have newer cells than this unixtime", Type: TypeInt},
+	{Name: "to", Description: "Count rows which have older cells than this unixtime", Type: TypeInt},
+	{Name: "by-prefix", Description: "Count rows grouped by the first <n> characters of the row key", Type: TypeInt, Validate: nonNegative},
+	{Name: "separator", Description: "Group by the first <n> segments split by <separator> instead of characters"},
+	{Name: "parallelism", Description: "Count with <n> workers splitting the table by the sampled row keys", Type: TypeInt, Validate: nonNegative},
+}
+
+// StatsOptions is the options of the stats command
+var StatsOptions = Options{
+	{Name: "start", Description: "Start scanning at this row", Complete: CompleteRowKey},
+	{Name: "end", Description: "Stop scanning before this row", Complete: CompleteRowKey},
+	{Name: "prefix", Description: "Scan rows with this prefix", Complete: CompleteRowKey},
+	{Name: "family", Description: "Scan only columns family with <columns_family>", Complete: CompleteFamily},
+	{Name: "sample", Description: "Scan only sampled rows with this ratio, the totals are estimated by the ratio", Type: TypeFloat, Validate: ratio},
+	{Name: "top", Description: "Print the largest <n> rows", Type: TypeInt, Default: "10", Validate: positive},
+}
+
+// TailOptions is the options of the tail command
+var TailOptions = Options{
+	{Name: "prefix", Description: "Watch rows with this prefix", Complete: CompleteRowKey},
+	{Name: "regex", Description: "Watch rows whose key matches this regex"},
+	{Name: "family", Description: "Watch only columns family with <columns_family>", Complete: CompleteFamily},
+	{Name: "interval", Description: "Poll the new cells every <interval>", Type: TypeDuration, Default: "2s", Validate: positiveDuration},
+	decodeOption,
+	decodeColumnsOption,
+}
+
+// DiffOptions is the options of the diff command
+var DiffOptions = Options{
+	{Name: "start", Description: "Start comparing at this row", Complete: CompleteRowKey},
+	{Name: "end", Description: "Stop comparing before this row", Complete: CompleteRowKey},
+	{Name: "prefix", Description: "Compare rows with this prefix", Complete: CompleteRowKey},
+	{Name: "regex", Description: "Compare rows whose key matches this regex"},
+	{Name: "family", Description: "Compare only columns family with <columns_family>", Complete: CompleteFamily},
+	{Name: "version", Description: "Compare only latest <n> columns", Type: TypeInt, Validate: nonNegative},
+	{Name: "summary-only", Description: "Print only the number of the different rows and cells", Type: TypeBool},
+}
+
+// SyncOptions is the options of the sync command
+var SyncOptions = Options{
+	{Name: "start", Description: "Start syncing at this row", Complete: CompleteRowKey},
+	{Name: "end", Description: "Stop syncing before this row", Complete: CompleteRowKey},
+	{Name: "prefix", Description: "Sync rows with this prefix", Complete: CompleteRowKey},
+	{Name: "regex", Description: "Sync rows whose key matches this regex"},
+	{Name: "family", Description: "Sync only columns family with <columns_family>", Complete: CompleteFamily},
+	{Name: "version", Description: "Sync only latest <n> columns", Type: TypeInt, Validate: nonNegative},
+	{Name: "summary-only", Description: "Report only the number of the different rows and cells", Type: TypeBool},
+	{Name: "dry-run", Description: "Report the difference without applying the mutations", Type: TypeBool},
+	{Name: "yes", Description: "Apply the mutations without the confirmation", Type: TypeBool},
+}
+
+// CopyOptions is the options of the copy command
+var CopyOptions = Options{
+	{Name: "start", Description: "Start copying at this row", Complete: CompleteRowKey},
+	{Name: "end", Description: "Stop copying before this row", Complete: CompleteRowKey},
+	{Name: "prefix", Description: "Copy rows with this prefix", Complete: CompleteRowKey},
+	{Name: "regex", Description: "Copy rows whose key matches this regex"},
+	{Name: "value", Description: "Copy cells with has value"},
+	{Name: "family", Description: "Copy only columns family with <columns_family>", Complete: CompleteFamily},
+	{Name: "version", Description: "Copy only latest <n> columns", Type: TypeInt, Validate: nonNegative},
+	{Name: "from", Description: "Copy cells whose version is newer than or equal to this unixtime", Type: TypeInt},
+	{Name: "to", Description: "Copy cells whose version is older than this unixtime", Type: TypeInt},
+	{Name: "count", Description: "Copy only <n> rows", Type: TypeInt, Validate: nonNegative},
+	{Name: "rename-family", Description: "Write the columns family <from> as <to>. <from>:<to>[,<from>:<to>...]", Validate: validRenames},
+	{Name: "key-transform", Description: "Rewrite the row keys by the regex. s/<regex>/<replacement>/", Validate: validKeyTransform},
+	{Name: "keep-timestamps", Description: "Write the cells with the source timestamps, or the server time", Type: TypeBool, Default: "true"},
+}
+
+// ExportOptions is the options of the export command
+var ExportOptions = Options{
+	{Name: "start", Description: "Start exporting at this row", Complete: CompleteRowKey},
+	{Name: "end", Description: "Stop exporting before this row", Complete: CompleteRowKey},
+	{Name: "prefix", Description: "Export rows with this prefix", Complete: CompleteRowKey},
+	{Name: "regex", Description: "Export rows whose key matches this regex"},
+	{Name: "value", Description: "Export cells with has value"},
+	{Name: "family", Description: "Export only columns family with <columns_family>", Complete: CompleteFamily},
+	{Name: "version", Description: "Export only latest <n> columns", Type: TypeInt, Validate: nonNegative},
+	{Name: "from", Description: "Export cells whose version is newer than or equal to this unixtime", Type: TypeInt},
+	{Name: "to", Description: "Export cells whose version is older than this unixtime", Type: TypeInt},
+	{Name: "format", Description: "File format", Values: []string{exportJSONL, exportCSV}, Default: exportJSONL},
+	{Name: "parallelism", Description: "Export with <n> workers splitting the table by the sampled row keys", Type: TypeInt, Validate: nonNegative},
+	{Name: "resume", Description: "Continue the interrupted export from the last exported row", Type: TypeBool},
+	decodeOption,
+	decodeColumnsOption,
+}
@@ -0,0 +1,97 @@
+package cbt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionsParse(t *testing.T) {
+	opts := Options{
+		{Name: "version", Type: TypeInt, Validate: nonNegative},
+		{Name: "decode", Values: []string{"int", "float"}},
+		{Name: "decode-columns", Aliases: []string{"decode_columns"}},
+		{Name: "ordered", Type: TypeBool, Default: "true"},
+	}
+	cases := []struct {
+		args   []string
+		expect Values
+		err    string
+	}{
+		{
+			[]string{"version=1", "decode_columns=a:int"},
+			Values{"version": "1", "decode-columns": "a:int", "ordered": "true"},
+			"",
+		},
+		{
+			[]string{"ordered=false", "decode=int"},
+			Values{"ordered": "false", "decode": "int"},
+			"",
+		},
+		{
+			[]string{"version=a"},
+			nil,
+			"Invalid value: version=a, must be int",
+		},
+		{
+			[]string{"version=-1"},
+			nil,
+			"Invalid value: version=-1, must not be negative",
+		},
+		{
+			[]string{"decode=string"},
+			nil,
+			"Invalid value: decode=string, must be one of <int|float>",
+		},
+		{
+			[]string{"version"},
+			nil,
+			"Invalid option: version",
+		},
+		{
+			[]string{"family=d"},
+			nil,
+			"Unknown option: family=d",
+		},
+	}
+	for _, c := range cases {
+		actual, err := opts.Parse(c.args)
+		if c.err != "" {
+			assert.EqualError(t, err, c.err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, c.expect, actual)
+	}
+}
+
+func TestOptionsSplit(t *testing.T) {
+	opts := Options{
+		{Name: "prefix"},
+		{Name: "version", Type: TypeInt},
+	}
+	positional, options := opts.Split([]string{"t1", "k=1", "prefix=1", "t2", "k=2", "version=1"})
+	assert.Equal(t, []string{"t1", "k=1", "t2", "k=2"}, positional)
+	assert.Equal(t, []string{"prefix=1", "version=1"}, options)
+}
+
+func TestOptionsUsage(t *testing.T) {
+	opts := Options{
+		{Name: "start", Description: "Start reading at this row"},
+		{Name: "ordered", Description: "Print in order", Type: TypeBool, Default: "true"},
+		{Name: "decode", Description: "Decode value", Values: []string{"int", "float"}},
+	}
+	expect := "\tstart          Start reading at this row\n" +
+		"\tordered        Print in order. <true|false>, default is true\n" +
+		"\tdecode         Decode value. <int|float>"
+	assert.Equal(t, expect, opts.Usage())
+}
+
+func TestValues(t *testing.T) {
+	v := Values{"count": "10", "sample": "0.5", "keys-only": "true", "invalid": "a"}
+	assert.Equal(t, int64(10), v.Int("count"))
+	assert.Equal(t, 0.5, v.Float("sample"))
+	assert.True(t, v.Bool("keys-only"))
+	assert.Equal(t, int64(0), v.Int("invalid"))
+	assert.False(t, v.Bool("none"))
+}
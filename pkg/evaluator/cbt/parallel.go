@@ -0,0 +1,100 @@
+package cbt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+	"github.com/takashabe/btcli/pkg/printer"
+)
+
+// progressInterval is the interval to print the progress of the parallel scan.
+var progressInterval = time.Second
+
+// parallelCount counts the rows with the parallel scan.
+// When groupFn is given, also counts the rows grouped by groupFn.
+func parallelCount(ctx context.Context, client bt.Client, table, begin, end string, parallelism int, groupFn func(string) string, opts ...bigtable.ReadOption) (int, map[string]int, error) {
+	var (
+		mu    sync.Mutex
+		total int64
+		cnts  = map[string]int{}
+	)
+	stopProgress := showProgress(client.ErrStream(), "counted", &total)
+	err := bt.ParallelScan(ctx, client, table, begin, end, parallelism, func(_ int, r *bt.Row) bool {
+		atomic.AddInt64(&total, 1)
+		if groupFn != nil {
+			mu.Lock()
+			cnts[groupFn(r.Key)]++
+			mu.Unlock()
+		}
+		return true
+	}, opts...)
+	stopProgress()
+
+	return int(atomic.LoadInt64(&total)), cnts, err
+}
+
+// parallelRead prints the rows with the parallel scan up to limit rows, and returns the number of printed rows and the last key.
+// When ordered is true, prints the rows in the key order with the bounded buffer per shard, otherwise as they arrive.
+func parallelRead(ctx context.Context, client bt.Client, table, begin, end string, parallelism int, ordered bool, limit int64, p *printer.Printer, opts ...bigtable.ReadOption) (int64, string, error) {
+	var (
+		total   int64
+		lastKey string
+	)
+	print := func(r *bt.Row) bool {
+		p.PrintRow(r)
+		total++
+		lastKey = r.Key
+		return limit == 0 || total < limit
+	}
+	if ordered {
+		err := bt.ParallelScanOrdered(ctx, client, table, begin, end, parallelism, print, opts...)
+		return total, lastKey, err
+	}
+
+	var mu sync.Mutex
+	err := bt.ParallelScan(ctx, client, table, begin, end, parallelism, func(_ int, r *bt.Row) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		// the other workers may send the rows after the limit
+		if limit > 0 && total >= limit {
+			return false
+		}
+		return print(r)
+	}, opts...)
+	return total, lastKey, err
+}
+
+// showProgress prints the number of rows periodically until the returned func is called.
+func showProgress(w io.Writer, label string, n *int64) func() {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		t := time.NewTicker(progressInterval)
+		defer t.Stop()
+
+		printed := false
+		for {
+			select {
+			case <-t.C:
+				fmt.Fprintf(w, "\r%s %d rows", label, atomic.LoadInt64(n))
+				printed = true
+			case <-done:
+				if printed {
+					fmt.Fprintln(w)
+				}
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
@@ -0,0 +1,78 @@
+package cbt
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+func TestDoReadWithParallelism(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cases := []struct {
+		input   []string
+		expect  []string
+		sorted  bool
+		prepare func(*bt.MockClient)
+	}{
+		{
+			[]string{"table", "parallelism=2", "keys-only=true"},
+			[]string{"a", "b", "c"},
+			true,
+			func(mock *bt.MockClient) {
+				mock.EXPECT().SampleRowKeys(gomock.Any(), "table").Return([]string{"b"}, nil)
+				mock.EXPECT().ReadRows(gomock.Any(), "table", bigtable.NewRange("", "b"), gomock.Any(), gomock.Any()).
+					DoAndReturn(readRowsFn("a"))
+				mock.EXPECT().ReadRows(gomock.Any(), "table", bigtable.InfiniteRange("b"), gomock.Any(), gomock.Any()).
+					DoAndReturn(readRowsFn("b", "c"))
+			},
+		},
+		{
+			[]string{"table", "parallelism=2", "keys-only=true", "count=2"},
+			[]string{"a", "b"},
+			true,
+			func(mock *bt.MockClient) {
+				mock.EXPECT().SampleRowKeys(gomock.Any(), "table").Return([]string{"b"}, nil)
+				mock.EXPECT().ReadRows(gomock.Any(), "table", bigtable.NewRange("", "b"), gomock.Any(), gomock.Any(), bigtable.LimitRows(2)).
+					DoAndReturn(readRowsFn("a"))
+				mock.EXPECT().ReadRows(gomock.Any(), "table", bigtable.InfiniteRange("b"), gomock.Any(), gomock.Any(), bigtable.LimitRows(2)).
+					DoAndReturn(readRowsFn("b", "c"))
+			},
+		},
+		{
+			[]string{"table", "parallelism=2", "keys-only=true", "ordered=false"},
+			[]string{"a", "b", "c"},
+			false,
+			func(mock *bt.MockClient) {
+				mock.EXPECT().SampleRowKeys(gomock.Any(), "table").Return([]string{"b"}, nil)
+				mock.EXPECT().ReadRows(gomock.Any(), "table", bigtable.NewRange("", "b"), gomock.Any(), gomock.Any()).
+					DoAndReturn(readRowsFn("a"))
+				mock.EXPECT().ReadRows(gomock.Any(), "table", bigtable.InfiniteRange("b"), gomock.Any(), gomock.Any()).
+					DoAndReturn(readRowsFn("b", "c"))
+			},
+		},
+	}
+	for _, c := range cases {
+		mockClient := bt.NewMockClient(ctrl)
+		c.prepare(mockClient)
+
+		var buf bytes.Buffer
+		mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+		mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+
+		DoRead(context.Background(), mockClient, c.input...)
+		actual := strings.Fields(buf.String())
+		if !c.sorted {
+			assert.ElementsMatch(t, c.expect, actual)
+			continue
+		}
+		assert.Equal(t, c.expect, actual)
+	}
+}
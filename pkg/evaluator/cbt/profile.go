@@ -0,0 +1,32 @@
+package cbt
+
+import (
+	"strings"
+
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+// ConnectProfile returns the client connected to the named profile, to refer the table of the other instance as "<profile>:<table>".
+// The profile is not available when nil.
+var ConnectProfile func(name string) (bt.Client, error)
+
+// resolveTable returns the client and the table name of "<profile>:<table>".
+// The table without the profile is returned with the given client.
+func resolveTable(client bt.Client, arg string) (bt.Client, string, error) {
+	i := strings.Index(arg, ":")
+	if i < 0 {
+		return client, arg, nil
+	}
+	name, table := arg[:i], arg[i+1:]
+	if name == "" || table == "" {
+		return nil, "", UsageError("Invalid table: %s", arg)
+	}
+	if ConnectProfile == nil {
+		return nil, "", UsageError("Profiles are not available: %s", arg)
+	}
+	c, err := ConnectProfile(name)
+	if err != nil {
+		return nil, "", WrapError(err)
+	}
+	return c, table, nil
+}
@@ -0,0 +1,203 @@
+package cbt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"cloud.google.com/go/bigtable"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+// DoStats prints the statistics of the table
+func DoStats(ctx context.Context, client bt.Client, args ...string) error {
+	if len(args) < 1 {
+		return UsageError("Invalid args: stats <table> [args ...]")
+	}
+	table := args[0]
+	opts := args[1:]
+
+	parsed, err := StatsOptions.Parse(opts)
+	if err != nil {
+		return err
+	}
+
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		return UsageError(`"start"/"end" may not be mixed with "prefix"`)
+	}
+
+	rr, err := rowRange(parsed)
+	if err != nil {
+		return UsageError("Invalid range: %v", err)
+	}
+	ro, err := statsOption(parsed)
+	if err != nil {
+		return UsageError("Invalid options: %v", err)
+	}
+	s := newTableStats(int(parsed.Int("top")))
+	if sample := parsed.Float("sample"); sample > 0 && sample < 1 {
+		s.sample = sample
+	}
+	err = client.ReadRows(ctx, table, rr, func(r *bt.Row) bool {
+		s.add(r)
+		return true
+	}, ro...)
+	if err != nil {
+		return WrapError(err)
+	}
+	s.print(client.OutStream())
+	return nil
+}
+
+func statsOption(parsedArgs map[string]string) ([]bigtable.ReadOption, error) {
+	fils, err := readFilters(parsedArgs)
+	if err != nil {
+		return nil, err
+	}
+	if sample := parsedArgs["sample"]; sample != "" {
+		p, err := strconv.ParseFloat(sample, 64)
+		if err != nil {
+			return nil, err
+		}
+		if p < 1 {
+			fils = append(fils, bigtable.RowSampleFilter(p))
+		}
+	}
+
+	if f := chainFilters(fils); f != nil {
+		return []bigtable.ReadOption{bigtable.RowFilter(f)}, nil
+	}
+	return nil, nil
+}
+
+// rowSize represents a size of the row
+type rowSize struct {
+	key  string
+	size int
+}
+
+// tableStats aggregates the statistics of the rows
+type tableStats struct {
+	rows     int
+	cells    int
+	bytes    int
+	maxCells int
+
+	columns     int
+	maxVersions int
+
+	familyBytes    map[string]int
+	qualifierBytes map[string]int
+
+	top     int
+	largest []rowSize
+
+	// sample is the ratio of the sampled rows, 0 when all rows are scanned
+	sample float64
+}
+
+func newTableStats(top int) *tableStats {
+	return &tableStats{
+		familyBytes:    map[string]int{},
+		qualifierBytes: map[string]int{},
+		top:            top,
+	}
+}
+
+// add aggregates the row. The size of the row is sum of the key, qualifiers and values.
+func (s *tableStats) add(r *bt.Row) {
+	size := len(r.Key)
+	versions := map[string]int{}
+	for _, c := range r.Columns {
+		cellSize := len(c.Qualifier) + len(c.Value)
+		size += cellSize
+		s.familyBytes[c.Family] += cellSize
+		s.qualifierBytes[c.Qualifier] += cellSize
+		versions[c.Qualifier]++
+	}
+
+	s.rows++
+	s.cells += len(r.Columns)
+	s.bytes += size
+	if len(r.Columns) > s.maxCells {
+		s.maxCells = len(r.Columns)
+	}
+	s.columns += len(versions)
+	for _, v := range versions {
+		if v > s.maxVersions {
+			s.maxVersions = v
+		}
+	}
+	s.addLargest(rowSize{key: r.Key, size: size})
+}
+
+// addLargest keeps the largest rows up to top in descending order.
+func (s *tableStats) addLargest(rs rowSize) {
+	i := sort.Search(len(s.largest), func(i int) bool {
+		return s.largest[i].size < rs.size
+	})
+	if i >= s.top {
+		return
+	}
+	s.largest = append(s.largest, rowSize{})
+	copy(s.largest[i+1:], s.largest[i:])
+	s.largest[i] = rs
+	if len(s.largest) > s.top {
+		s.largest = s.largest[:s.top]
+	}
+}
+
+func (s *tableStats) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	if s.sample > 0 {
+		fmt.Fprintf(tw, "sampled\t%g, the totals are estimated by the ratio\n", s.sample)
+	}
+	fmt.Fprintf(tw, "rows\t%d\n", s.estimate(s.rows))
+	fmt.Fprintf(tw, "cells\t%d\n", s.estimate(s.cells))
+	fmt.Fprintf(tw, "total bytes\t%d\n", s.estimate(s.bytes))
+	fmt.Fprintf(tw, "average row bytes\t%.2f\n", average(s.bytes, s.rows))
+	fmt.Fprintf(tw, "cells per row\tavg %.2f, max %d\n", average(s.cells, s.rows), s.maxCells)
+	fmt.Fprintf(tw, "versions per column\tavg %.2f, max %d\n", average(s.cells, s.columns), s.maxVersions)
+
+	fmt.Fprintln(tw, "\nfamily\tbytes")
+	s.printBytes(tw, s.familyBytes)
+
+	fmt.Fprintln(tw, "\nqualifier\tbytes")
+	s.printBytes(tw, s.qualifierBytes)
+
+	fmt.Fprintln(tw, "\nlargest rows\tbytes")
+	for _, rs := range s.largest {
+		fmt.Fprintf(tw, "%s\t%d\n", rs.key, rs.size)
+	}
+	tw.Flush()
+}
+
+// estimate scales the total of the sampled rows to the whole range.
+func (s *tableStats) estimate(n int) int {
+	if s.sample == 0 {
+		return n
+	}
+	return int(math.Round(float64(n) / s.sample))
+}
+
+func (s *tableStats) printBytes(w io.Writer, m map[string]int) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%d\n", k, s.estimate(m[k]))
+	}
+}
+
+func average(sum, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return float64(sum) / float64(n)
+}
@@ -0,0 +1,132 @@
+package cbt
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+func TestStatsOption(t *testing.T) {
+	cases := []struct {
+		input  map[string]string
+		expect []bigtable.ReadOption
+		err    bool
+	}{
+		{
+			map[string]string{},
+			nil,
+			false,
+		},
+		{
+			map[string]string{"sample": "0.5"},
+			[]bigtable.ReadOption{bigtable.RowFilter(bigtable.RowSampleFilter(0.5))},
+			false,
+		},
+		{
+			map[string]string{"sample": "1", "family": "d"},
+			[]bigtable.ReadOption{bigtable.RowFilter(bigtable.FamilyFilter("^d$"))},
+			false,
+		},
+	}
+	for _, c := range cases {
+		actual, err := statsOption(c.input)
+		if c.err {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, c.expect, actual)
+	}
+}
+
+func TestDoStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rows := []*bt.Row{
+		{
+			Key: "1",
+			Columns: []*bt.Column{
+				{Family: "d", Qualifier: "d:row", Value: []byte("madoka")},
+			},
+		},
+		{
+			Key: "4",
+			Columns: []*bt.Column{
+				{Family: "d", Qualifier: "d:row", Value: []byte("anko")},
+				{Family: "d", Qualifier: "d:row", Value: []byte("kyouko")},
+				{Family: "e", Qualifier: "e:age", Value: []byte("14")},
+			},
+		},
+	}
+
+	mockClient := bt.NewMockClient(ctrl)
+	readRows := func(_ context.Context, _ string, _ bigtable.RowRange, fn func(*bt.Row) bool, _ ...bigtable.ReadOption) error {
+		for _, r := range rows {
+			fn(r)
+		}
+		return nil
+	}
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users", bigtable.PrefixRange("1"), gomock.Any()).
+		DoAndReturn(readRows)
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users", bigtable.PrefixRange("1"), gomock.Any(), gomock.Any()).
+		DoAndReturn(readRows)
+
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+
+	DoStats(context.Background(), mockClient, "users", "prefix=1", "top=1")
+	expect := `rows                 2
+cells                4
+total bytes          40
+average row bytes    20.00
+cells per row        avg 2.00, max 3
+versions per column  avg 1.33, max 2
+
+family  bytes
+d       31
+e       7
+
+qualifier  bytes
+d:row      31
+e:age      7
+
+largest rows  bytes
+4             28
+`
+	assert.Equal(t, expect, buf.String())
+
+	// the totals are scaled by the ratio
+	buf.Reset()
+	DoStats(context.Background(), mockClient, "users", "prefix=1", "top=1", "sample=0.5")
+	expect = `sampled              0.5, the totals are estimated by the ratio
+rows                 4
+cells                8
+total bytes          80
+average row bytes    20.00
+cells per row        avg 2.00, max 3
+versions per column  avg 1.33, max 2
+
+family  bytes
+d       62
+e       14
+
+qualifier  bytes
+d:row      62
+e:age      14
+
+largest rows  bytes
+4             28
+`
+	assert.Equal(t, expect, buf.String())
+
+	// top must be positive
+	err := DoStats(context.Background(), mockClient, "users", "top=0")
+	assert.Equal(t, KindUsage, KindOf(err))
+}
@@ -0,0 +1,207 @@
+package cbt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+// DoSync applies the minimal mutations to make the rows of the destination table match the source table.
+// The difference is printed as the dry-run report first, and the range is compared again to apply the mutations by the batches after the confirmation.
+func DoSync(ctx context.Context, client bt.Client, args ...string) error {
+	positional, opts := SyncOptions.Split(args)
+	if len(positional) != 2 {
+		return UsageError("Invalid args: sync <src-table> <dst-table> [args ...]")
+	}
+	parsed, err := SyncOptions.Parse(opts)
+	if err != nil {
+		return err
+	}
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		return UsageError(`"start"/"end" may not be mixed with "prefix"`)
+	}
+
+	src, srcTable, err := resolveTable(client, positional[0])
+	if err != nil {
+		return err
+	}
+	dst, dstTable, err := resolveTable(client, positional[1])
+	if err != nil {
+		return err
+	}
+
+	// the first pass only counts the rows, to keep the memory of the large range
+	out := client.OutStream()
+	plan := &syncPlan{
+		// the row may have the other cells out of the filter
+		deleteRows: parsed["family"] == "" && parsed["version"] == "",
+	}
+	d := &differ{
+		out:         out,
+		labelA:      positional[0],
+		labelB:      positional[1],
+		summaryOnly: parsed.Bool("summary-only"),
+		onDiff:      plan.count,
+	}
+	if err := d.diffTables(ctx, src, srcTable, dst, dstTable, parsed); err != nil {
+		return err
+	}
+	if plan.updates+plan.deletes == 0 {
+		fmt.Fprintf(out, "%s is already in sync\n", positional[1])
+		return nil
+	}
+	fmt.Fprintf(out, "%d rows to be updated, %d rows to be deleted in %s\n", plan.updates, plan.deletes, positional[1])
+	if parsed.Bool("dry-run") {
+		return nil
+	}
+	if !parsed.Bool("yes") {
+		// the question in the redirected output can not be answered
+		if !IsTerminal(out) {
+			return UsageError("The output is not a terminal, use yes=true to apply the mutations without the confirmation")
+		}
+		if !confirm(client.ErrStream(), fmt.Sprintf("Apply the mutations to %s? [y/N] ", positional[1])) {
+			fmt.Fprintln(out, "Canceled")
+			return nil
+		}
+	}
+
+	// the second pass compares the range again, and applies the mutations by the batches
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	a := &syncApplier{
+		ctx:        ctx,
+		cancel:     cancel,
+		client:     dst,
+		table:      dstTable,
+		errOut:     client.ErrStream(),
+		deleteRows: plan.deleteRows,
+	}
+	d = &differ{
+		out:         ioutil.Discard,
+		summaryOnly: true,
+		onDiff:      a.add,
+	}
+	err = d.diffTables(ctx, src, srcTable, dst, dstTable, parsed)
+	if err == nil {
+		a.flush()
+	}
+	fmt.Fprintf(out, "%d rows synced\n", a.applied)
+	if a.err != nil {
+		return WrapError(a.err)
+	}
+	if err != nil {
+		return err
+	}
+	if a.failed > 0 {
+		return WrapError(fmt.Errorf("%d rows failed to sync", a.failed))
+	}
+	return nil
+}
+
+// confirm prints the question to the terminal and returns whether the answer is yes.
+func confirm(w io.Writer, question string) bool {
+	fmt.Fprint(w, question)
+	line, err := readLine(moreInput)
+	if err != nil && line == "" {
+		fmt.Fprintln(w)
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// syncPlan is the number of the destination rows to be updated or deleted
+type syncPlan struct {
+	deleteRows bool
+
+	updates int
+	deletes int
+}
+
+// count counts the destination row to be updated or deleted.
+func (p *syncPlan) count(src, dst *bt.Row, cells []cellDiff) {
+	if len(src.Columns) == 0 && p.deleteRows {
+		p.deletes++
+	} else {
+		p.updates++
+	}
+}
+
+// syncApplier applies the mutations of the destination rows by the bulk requests of bulkBatchSize rows
+type syncApplier struct {
+	ctx        context.Context
+	cancel     func()
+	client     bt.Client
+	table      string
+	errOut     io.Writer
+	deleteRows bool
+
+	keys []string
+	muts []*bigtable.Mutation
+
+	applied int
+	failed  int
+	// err is the failure of the request, stops the comparison
+	err error
+}
+
+// add adds the mutation to write the source cells and to delete the extra cells of the destination row.
+func (a *syncApplier) add(src, dst *bt.Row, cells []cellDiff) {
+	if a.err != nil {
+		return
+	}
+	m := bigtable.NewMutation()
+	if len(src.Columns) == 0 && a.deleteRows {
+		m.DeleteRow()
+	} else {
+		for _, c := range cells {
+			switch c.op {
+			case diffRemoved, diffChanged:
+				family, column := splitColumn(c.old)
+				m.Set(family, column, bigtable.Time(c.old.Version), c.old.Value)
+			case diffAdded:
+				family, column := splitColumn(c.new)
+				// the timestamp of the cell is in milliseconds
+				ts := bigtable.Time(c.new.Version)
+				m.DeleteTimestampRange(family, column, ts, ts+bigtable.Timestamp(time.Millisecond/time.Microsecond))
+			}
+		}
+	}
+	a.keys = append(a.keys, dst.Key)
+	a.muts = append(a.muts, m)
+	if len(a.keys) >= bulkBatchSize {
+		a.flush()
+	}
+}
+
+// flush applies the buffered mutations, and prints the rows failed to apply.
+func (a *syncApplier) flush() {
+	if len(a.keys) == 0 {
+		return
+	}
+	applied, err := applyBulk(a.ctx, a.client, a.table, a.keys, a.muts, func(key string, err error) {
+		fmt.Fprintf(a.errOut, "Failed to sync %s: %v\n", key, err)
+		a.failed++
+	})
+	a.applied += applied
+	a.keys, a.muts = a.keys[:0], a.muts[:0]
+	if err != nil {
+		a.err = err
+		a.cancel()
+	}
+}
+
+// splitColumn returns the family and the column name of the qualifier "<family>:<column>".
+func splitColumn(c *bt.Column) (string, string) {
+	return c.Family, strings.TrimPrefix(c.Qualifier, c.Family+":")
+}
@@ -0,0 +1,184 @@
+package cbt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+// expectSyncRows expects reading the rows of users and users_v2 to compare.
+func expectSyncRows(mockClient *bt.MockClient) {
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users", gomock.Any(), gomock.Any()).DoAndReturn(readRowsFrom(
+		&bt.Row{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}},
+		&bt.Row{Key: "10", Columns: []*bt.Column{diffColumn("d:row", "homura", 1)}},
+		&bt.Row{Key: "12", Columns: []*bt.Column{diffColumn("d:row", "sayaka", 1), diffColumn("d:age", "14", 1)}},
+	))
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users_v2", gomock.Any(), gomock.Any()).DoAndReturn(readRowsFrom(
+		&bt.Row{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}},
+		&bt.Row{Key: "11", Columns: []*bt.Column{diffColumn("d:row", "kyouko", 1)}},
+		&bt.Row{Key: "12", Columns: []*bt.Column{diffColumn("d:row", "sayaka!", 1), diffColumn("d:tag", "x", 2)}},
+	))
+}
+
+func TestDoSync(t *testing.T) {
+	defer func(f func(io.Writer) bool) { IsTerminal = f }(IsTerminal)
+	IsTerminal = func(io.Writer) bool { return true }
+
+	ts := bigtable.Time(time.Unix(1, 0))
+	m10 := bigtable.NewMutation()
+	m10.Set("d", "row", ts, []byte("homura"))
+	m11 := bigtable.NewMutation()
+	m11.DeleteRow()
+	m12 := bigtable.NewMutation()
+	m12.Set("d", "age", ts, []byte("14"))
+	m12.Set("d", "row", ts, []byte("sayaka"))
+	m12.DeleteTimestampRange("d", "tag", ts+1000000, ts+1001000)
+
+	cases := []struct {
+		args   []string
+		input  string
+		apply  bool
+		expect string
+	}{
+		{
+			args:   []string{"dry-run=true"},
+			expect: "4 rows compared, 3 rows differ (1 only in users, 1 only in users_v2)\n2 cells added, 2 removed, 1 changed\n2 rows to be updated, 1 rows to be deleted in users_v2\n",
+		},
+		{
+			args:   []string{"summary-only=true"},
+			input:  "n\n",
+			expect: "Apply the mutations to users_v2? [y/N] Canceled\n",
+		},
+		{
+			args:   []string{"summary-only=true"},
+			input:  "y\n",
+			apply:  true,
+			expect: "Apply the mutations to users_v2? [y/N] 3 rows synced\n",
+		},
+		{
+			args:   []string{"summary-only=true", "yes=true"},
+			apply:  true,
+			expect: "users_v2\n3 rows synced\n",
+		},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+
+		mockClient := bt.NewMockClient(ctrl)
+		var buf bytes.Buffer
+		mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+		mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+		expectSyncRows(mockClient)
+		if c.apply {
+			// compared again to apply
+			expectSyncRows(mockClient)
+			mockClient.EXPECT().ApplyBulk(gomock.Any(), "users_v2", []string{"10", "11", "12"}, []*bigtable.Mutation{m10, m11, m12}).Return(nil, nil)
+		}
+		moreInput = strings.NewReader(c.input)
+
+		err := DoSync(context.Background(), mockClient, append([]string{"users", "users_v2"}, c.args...)...)
+		assert.NoError(t, err, "args: %v", c.args)
+		assert.True(t, strings.HasSuffix(buf.String(), c.expect), "args: %v, got: %q", c.args, buf.String())
+		ctrl.Finish()
+	}
+}
+
+func TestDoSyncFailedRows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var out, errOut bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&out).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&errOut).AnyTimes()
+	expectSyncRows(mockClient)
+	expectSyncRows(mockClient)
+	mockClient.EXPECT().ApplyBulk(gomock.Any(), "users_v2", gomock.Any(), gomock.Any()).Return([]error{nil, errors.New("unavailable"), nil}, nil)
+
+	err := DoSync(context.Background(), mockClient, "users", "users_v2", "summary-only=true", "yes=true")
+	assert.Equal(t, KindServer, KindOf(err))
+	assert.True(t, strings.HasSuffix(out.String(), "2 rows synced\n"), out.String())
+	assert.Equal(t, "Failed to sync 11: unavailable\n", errOut.String())
+}
+
+func TestDoSyncRedirected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	expectSyncRows(mockClient)
+
+	// the confirmation is not available
+	err := DoSync(context.Background(), mockClient, "users", "users_v2", "summary-only=true")
+	assert.Equal(t, KindUsage, KindOf(err))
+	assert.NotContains(t, buf.String(), "[y/N]")
+}
+
+func TestDoSyncBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ErrStream().Return(&buf).AnyTimes()
+	rows := make([]*bt.Row, 0, bulkBatchSize+50)
+	for i := 0; i < cap(rows); i++ {
+		key := fmt.Sprintf("%03d", i)
+		rows = append(rows, &bt.Row{Key: key, Columns: []*bt.Column{diffColumn("d:row", key, 1)}})
+	}
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users", gomock.Any(), gomock.Any()).DoAndReturn(readRowsFrom(rows...)).Times(2)
+	mockClient.EXPECT().ReadRows(gomock.Any(), "users_v2", gomock.Any(), gomock.Any()).DoAndReturn(readRowsFrom()).Times(2)
+
+	// the mutations are applied while comparing
+	var sizes []int
+	mockClient.EXPECT().ApplyBulk(gomock.Any(), "users_v2", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, keys []string, _ []*bigtable.Mutation) ([]error, error) {
+			sizes = append(sizes, len(keys))
+			return nil, nil
+		}).Times(2)
+
+	err := DoSync(context.Background(), mockClient, "users", "users_v2", "summary-only=true", "yes=true")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{bulkBatchSize, 50}, sizes)
+	assert.True(t, strings.HasSuffix(buf.String(), "150 rows synced\n"), buf.String())
+}
+
+func TestDoSyncInSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+	mockClient.EXPECT().ReadRows(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(readRowsFrom(
+		&bt.Row{Key: "1", Columns: []*bt.Column{diffColumn("d:row", "madoka", 1)}},
+	)).Times(2)
+
+	err := DoSync(context.Background(), mockClient, "users", "users_v2")
+	assert.NoError(t, err)
+	assert.Equal(t, "1 rows compared, 0 rows differ (0 only in users, 0 only in users_v2)\n"+
+		"0 cells added, 0 removed, 0 changed\n"+
+		"users_v2 is already in sync\n", buf.String())
+
+	for _, args := range [][]string{
+		{"users"},
+		{"users", "1", "users", "2"},
+		{"users", "users_v2", "prefix=1", "start=1"},
+	} {
+		err := DoSync(context.Background(), mockClient, args...)
+		assert.Equal(t, KindUsage, KindOf(err), "args: %v", args)
+	}
+}
@@ -0,0 +1,80 @@
+package cbt
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+// DoTail polls the cells newer than the last poll and prints them until interrupted by Ctrl-C.
+// The cells are selected by the timestamp, so the cells written with an older timestamp than the last poll are not printed.
+func DoTail(ctx context.Context, client bt.Client, args ...string) error {
+	if len(args) < 1 {
+		return UsageError("Invalid args: tail <table> [args ...]")
+	}
+	table := args[0]
+	opts := args[1:]
+
+	parsed, err := TailOptions.Parse(opts)
+	if err != nil {
+		return err
+	}
+	rr, err := rowRange(parsed)
+	if err != nil {
+		return UsageError("Invalid range: %v", err)
+	}
+	fils, err := readFilters(parsed)
+	if err != nil {
+		return UsageError("Invalid options: %v", err)
+	}
+	interval := parsed.Duration("interval")
+
+	p := NewPrinter(client.OutStream(), parsed)
+
+	ctx, stop := WithInterrupt(ctx)
+	defer stop()
+
+	// the timestamp of the cell is in milliseconds
+	lastSeen := time.Now().Truncate(time.Millisecond)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+
+		now := time.Now().Truncate(time.Millisecond)
+		f := chainFilters(append(fils[:len(fils):len(fils)], bigtable.TimestampRangeFilter(lastSeen, now)))
+		b, err := client.GetRows(ctx, table, rr, bigtable.RowFilter(f))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return WrapError(err)
+		}
+		p.PrintRows(b.Rows)
+		lastSeen = now
+	}
+}
+
+// WithInterrupt returns the context canceled by Ctrl-C. stop cancels the context and releases the signal.
+func WithInterrupt(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sigCh)
+		cancel()
+	}
+}
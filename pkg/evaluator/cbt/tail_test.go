@@ -0,0 +1,63 @@
+package cbt
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bt "github.com/takashabe/btcli/pkg/bigtable"
+)
+
+func TestDoTail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	var buf bytes.Buffer
+	mockClient.EXPECT().OutStream().Return(&buf).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	version := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	gomock.InOrder(
+		mockClient.EXPECT().GetRows(gomock.Any(), "users", bigtable.PrefixRange("1"), gomock.Any()).Return(&bt.Bigtable{
+			Table: "users",
+			Rows: []*bt.Row{
+				{Key: "1", Columns: []*bt.Column{{Family: "d", Qualifier: "d:row", Value: []byte("madoka"), Version: version}}},
+			},
+		}, nil),
+		mockClient.EXPECT().GetRows(gomock.Any(), "users", bigtable.PrefixRange("1"), gomock.Any()).Return(&bt.Bigtable{Table: "users"}, nil),
+		mockClient.EXPECT().GetRows(gomock.Any(), "users", bigtable.PrefixRange("1"), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ string, _ bigtable.RowRange, _ ...bigtable.ReadOption) (*bt.Bigtable, error) {
+				// interrupted while reading
+				cancel()
+				return nil, context.Canceled
+			}),
+	)
+
+	err := DoTail(ctx, mockClient, "users", "prefix=1", "interval=1ms")
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "1\n  d:row")
+	assert.Contains(t, buf.String(), `"madoka"`)
+}
+
+func TestDoTailInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := bt.NewMockClient(ctrl)
+	cases := [][]string{
+		{},
+		{"users", "interval=0s"},
+		{"users", "interval=2"},
+		{"users", "count=1"},
+	}
+	for _, c := range cases {
+		err := DoTail(context.Background(), mockClient, c...)
+		assert.Equal(t, KindUsage, KindOf(err), "args: %v", c)
+	}
+}
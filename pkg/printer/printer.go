@@ -17,13 +17,28 @@ const (
 	DecodeTypeFloat  = "float"
 )
 
+// DecodeTypes returns the supported decode types.
+func DecodeTypes() []string {
+	return []string{DecodeTypeString, DecodeTypeInt, DecodeTypeFloat}
+}
+
 // Printer print the bigtable items to stream
 type Printer struct {
 	OutStream        io.Writer
 	DecodeType       string
 	DecodeColumnType map[string]string
+
+	// KeysOnly prints only the row keys
+	KeysOnly bool
+	// StripValues prints the columns without the values
+	StripValues bool
+	// Highlight returns the escape sequence to color the column, or empty not to color
+	Highlight func(key string, c *bigtable.Column) string
 }
 
+// colorReset resets the color set by Highlight
+const colorReset = "\x1b[0m"
+
 // PrintRows prints the list of values.
 func (w *Printer) PrintRows(rs []*bigtable.Row) {
 	for _, r := range rs {
@@ -33,16 +48,36 @@ func (w *Printer) PrintRows(rs []*bigtable.Row) {
 
 // PrintRow prints the value.
 func (w *Printer) PrintRow(r *bigtable.Row) {
+	if w.KeysOnly {
+		fmt.Fprintln(w.OutStream, r.Key)
+		return
+	}
+
 	fmt.Fprintln(w.OutStream, strings.Repeat("-", 40))
 	fmt.Fprintln(w.OutStream, r.Key)
 
 	for _, c := range r.Columns {
+		color := ""
+		if w.Highlight != nil {
+			color = w.Highlight(r.Key, c)
+		}
+		fmt.Fprint(w.OutStream, color)
 		fmt.Fprintf(w.OutStream, "  %-40s @ %s\n", c.Qualifier, c.Version.Format("2006/01/02-15:04:05.000000"))
-		w.printValue(c.Qualifier, c.Value)
+		if !w.StripValues {
+			w.printValue(c.Qualifier, c.Value)
+		}
+		if color != "" {
+			fmt.Fprint(w.OutStream, colorReset)
+		}
 	}
 }
 
 func (w *Printer) printValue(q string, v []byte) {
+	w.doPrint(w.ColumnDecodeType(q), v)
+}
+
+// ColumnDecodeType returns the decode type of the qualifier, the type of the column is preferred to DecodeType.
+func (w *Printer) ColumnDecodeType(q string) string {
 	// extract columnName in a qualifier
 	// qualifier format: "columnFamily:columnName"
 	q = q[strings.Index(q, ":")+1:]
@@ -51,13 +86,23 @@ func (w *Printer) printValue(q string, v []byte) {
 	// decodeColumns format "column1:type1,column2:type2,..."
 	for column, decode := range w.DecodeColumnType {
 		if q == column {
-			w.doPrint(decode, v)
-			return
+			return decode
 		}
 	}
+	return w.DecodeType
+}
 
-	// invoke print with a general DecodeType
-	w.doPrint(w.DecodeType, v)
+// Decode returns the 8 bytes big-endian value as int64 or float64 by the decode type, otherwise as string.
+func Decode(decode string, v []byte) interface{} {
+	if len(v) == 8 {
+		switch decode {
+		case DecodeTypeInt:
+			return byte2Int(v)
+		case DecodeTypeFloat:
+			return byte2Float(v)
+		}
+	}
+	return string(v)
 }
 
 func (w *Printer) doPrint(decode string, v []byte) {
@@ -68,20 +113,20 @@ func (w *Printer) doPrint(decode string, v []byte) {
 
 	switch decode {
 	case DecodeTypeInt:
-		fmt.Fprintf(w.OutStream, "    %d\n", w.byte2Int(v))
+		fmt.Fprintf(w.OutStream, "    %d\n", byte2Int(v))
 	case DecodeTypeFloat:
-		fmt.Fprintf(w.OutStream, "    %f\n", w.byte2Float(v))
+		fmt.Fprintf(w.OutStream, "    %f\n", byte2Float(v))
 	case DecodeTypeString:
 	default:
 		fmt.Fprintf(w.OutStream, "    %q\n", v)
 	}
 }
 
-func (*Printer) byte2Int(b []byte) int64 {
+func byte2Int(b []byte) int64 {
 	return (int64)(binary.BigEndian.Uint64(b))
 }
 
-func (*Printer) byte2Float(b []byte) float64 {
+func byte2Float(b []byte) float64 {
 	bits := binary.BigEndian.Uint64(b)
 	return math.Float64frombits(bits)
 }
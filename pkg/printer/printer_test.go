@@ -11,10 +11,12 @@ import (
 
 func TestPrintRows(t *testing.T) {
 	cases := []struct {
-		input  *bigtable.Row
-		expect string
+		printer *Printer
+		input   *bigtable.Row
+		expect  string
 	}{
 		{
+			&Printer{},
 			&bigtable.Row{
 				Key: "a",
 				Columns: []*bigtable.Column{
@@ -27,14 +29,64 @@ func TestPrintRows(t *testing.T) {
 			},
 			"----------------------------------------\na\n  d:row                                    @ 0001/01/01-00:00:00.000000\n    \"a1\"\n",
 		},
+		{
+			&Printer{KeysOnly: true},
+			&bigtable.Row{
+				Key: "a",
+				Columns: []*bigtable.Column{
+					{
+						Family:    "d",
+						Qualifier: "d:row",
+					},
+				},
+			},
+			"a\n",
+		},
+		{
+			&Printer{StripValues: true},
+			&bigtable.Row{
+				Key: "a",
+				Columns: []*bigtable.Column{
+					{
+						Family:    "d",
+						Qualifier: "d:row",
+					},
+				},
+			},
+			"----------------------------------------\na\n  d:row                                    @ 0001/01/01-00:00:00.000000\n",
+		},
+		{
+			&Printer{Highlight: func(key string, c *bigtable.Column) string {
+				if c.Qualifier == "d:new" {
+					return "\x1b[32m"
+				}
+				return ""
+			}},
+			&bigtable.Row{
+				Key: "a",
+				Columns: []*bigtable.Column{
+					{
+						Family:    "d",
+						Qualifier: "d:new",
+						Value:     []byte("a1"),
+					},
+					{
+						Family:    "d",
+						Qualifier: "d:row",
+						Value:     []byte("a2"),
+					},
+				},
+			},
+			"----------------------------------------\na\n" +
+				"\x1b[32m  d:new                                    @ 0001/01/01-00:00:00.000000\n    \"a1\"\n\x1b[0m" +
+				"  d:row                                    @ 0001/01/01-00:00:00.000000\n    \"a2\"\n",
+		},
 	}
 	for _, c := range cases {
 		var buf bytes.Buffer
-		printer := &Printer{
-			OutStream: &buf,
-		}
+		c.printer.OutStream = &buf
 
-		printer.PrintRow(c.input)
+		c.printer.PrintRow(c.input)
 		assert.Equal(t, c.expect, buf.String())
 	}
 }
@@ -101,3 +153,26 @@ func TestPrintValue(t *testing.T) {
 		assert.Equal(t, c.expect, strings.TrimSpace(buf.String()))
 	}
 }
+
+func TestDecode(t *testing.T) {
+	p := &Printer{
+		DecodeType:       "string",
+		DecodeColumnType: map[string]string{"age": "int"},
+	}
+	assert.Equal(t, "int", p.ColumnDecodeType("d:age"))
+	assert.Equal(t, "string", p.ColumnDecodeType("d:row"))
+
+	cases := []struct {
+		decode string
+		value  []byte
+		expect interface{}
+	}{
+		{"int", []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}, int64(1)},
+		{"float", []byte{0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, 2.0},
+		{"int", []byte("1"), "1"},
+		{"string", []byte("madoka"), "madoka"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expect, Decode(c.decode, c.value))
+	}
+}